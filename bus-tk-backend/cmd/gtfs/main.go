@@ -0,0 +1,275 @@
+// Command gtfs converts between GTFS static feeds and the backend's data files.
+//
+// Both subcommands read and write the files the server is configured with
+// (ROUTES_PATH, FARE_CHART_PATH and the LOCATION_STORE).
+//
+//	go run ./cmd/gtfs import -feed dhaka.zip
+//	go run ./cmd/gtfs export -out bus-tk-gtfs.zip
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/config"
+	"github.com/spectrum/bus-tk-backend/gtfs"
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/storage"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// stopMatchMeters is how close a same-named location must be to count as the stop
+const stopMatchMeters = 500
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "import":
+		runImport(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gtfs import -feed <feed.zip> [-bus-type nonAC|AC] [-dry-run] [-replace]")
+	fmt.Fprintln(os.Stderr, "       gtfs export [-out bus-tk-gtfs.zip]")
+	os.Exit(2)
+}
+
+// runImport reads a feed, reports validation issues and writes the route
+// network, the fare chart and any new stop locations where the server reads them
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	feedPath := flags.String("feed", "", "path to the GTFS zip")
+	busType := flags.String("bus-type", string(models.BusTypeNonAC), "bus type given to imported routes")
+	chartVersion := flags.String("chart-version", "gtfs-"+time.Now().Format("2006-01-02"), "version recorded in the fare chart")
+	dryRun := flags.Bool("dry-run", false, "validate and summarize without writing files")
+	replace := flags.Bool("replace", false, "replace an existing route network and fare chart, keeping .bak copies")
+	flags.Parse(args)
+
+	if *feedPath == "" {
+		usage()
+	}
+	if *busType != string(models.BusTypeNonAC) && *busType != string(models.BusTypeAC) {
+		log.Fatalf("Invalid bus type %q", *busType)
+	}
+
+	feed, issues, err := gtfs.ReadZip(*feedPath)
+	if err != nil {
+		log.Fatalf("Error reading feed: %v", err)
+	}
+	issues = append(issues, gtfs.Validate(feed)...)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if gtfs.HasErrors(issues) {
+		log.Fatalf("Feed has errors, nothing imported")
+	}
+
+	network, chart := gtfs.ToNetwork(feed, gtfs.ConvertOptions{BusType: *busType, ChartVersion: *chartVersion})
+
+	cfg := config.Load()
+	locationStore, err := storage.Open(storage.Kind(cfg.LocationStore), cfg.LocationsPath, cfg.LocationsDBPath)
+	if err != nil {
+		log.Fatalf("Error opening location store: %v", err)
+	}
+	defer locationStore.Close()
+	locations, err := loadLocations(cfg, locationStore)
+	if err != nil {
+		log.Fatalf("Error reading locations: %v", err)
+	}
+	added := linkStops(&network, &locations)
+	if _, err := services.AssignLocationIDs(locations); err != nil {
+		log.Fatalf("Error assigning location IDs: %v", err)
+	}
+	for i := range network.Stops {
+		if index, ok := stopLocation(network.Stops[i], locations); ok {
+			network.Stops[i].LocationID = locations[index].ID
+		}
+	}
+
+	// Reject anything the server would refuse to load, and a chart for other routes
+	routeService, err := services.NewRouteService(network)
+	if err != nil {
+		log.Fatalf("Error in converted route network: %v", err)
+	}
+	if err := services.ValidateLocations(locations); err != nil {
+		log.Fatalf("Error in locations: %v", err)
+	}
+	if err := services.ValidateFareChart(chart); err != nil {
+		log.Fatalf("Error in converted fare chart: %v", err)
+	}
+	if err := routeService.ValidateFareChart(chart); err != nil {
+		log.Fatalf("Error in converted fare chart: %v", err)
+	}
+
+	fmt.Printf("Operators: %d, stops: %d (%d new locations), routes: %d, fare chart routes: %d\n",
+		len(network.Operators), len(network.Stops), added, len(network.Routes), len(chart.Routes))
+
+	// The import replaces the whole network, so an existing one is only overwritten on request
+	_, statErr := os.Stat(cfg.RoutesPath)
+	exists := statErr == nil
+	if exists {
+		if current, err := services.LoadRouteNetwork(cfg.RoutesPath); err == nil {
+			fmt.Printf("Replaces %s: %d routes, %d stops\n", cfg.RoutesPath, len(current.Routes), len(current.Stops))
+		} else {
+			fmt.Printf("Replaces %s: %v\n", cfg.RoutesPath, err)
+		}
+	}
+	if *dryRun {
+		return
+	}
+	if exists && !*replace {
+		log.Fatalf("%s already exists; pass -replace to overwrite it and the fare chart", cfg.RoutesPath)
+	}
+	for _, path := range []string{cfg.RoutesPath, cfg.FareChartPath} {
+		if err := backupFile(path); err != nil {
+			log.Fatalf("Error backing up %s: %v", path, err)
+		}
+	}
+
+	if err := utils.WriteJSONFile(cfg.RoutesPath, network); err != nil {
+		log.Fatalf("Error writing route network: %v", err)
+	}
+	// The chart is always replaced: a feed without fares must not leave the old
+	// chart pricing the new routes
+	if len(chart.Routes) == 0 {
+		fmt.Println("The feed has no fares; the fare chart is emptied and quotes use the per-km fares")
+	}
+	if err := utils.WriteJSONFile(cfg.FareChartPath, chart); err != nil {
+		log.Fatalf("Error writing fare chart: %v", err)
+	}
+	if added > 0 {
+		if err := locationStore.SaveLocations(locations); err != nil {
+			log.Fatalf("Error writing locations: %v", err)
+		}
+	}
+}
+
+// runExport writes the configured route network and fares as a GTFS zip,
+// reading the same files as the server
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	outPath := flags.String("out", "bus-tk-gtfs.zip", "path of the GTFS zip to write")
+	flags.Parse(args)
+
+	cfg := config.Load()
+
+	fareTables, err := services.LoadFareTables(cfg.FareTablesPath)
+	if err != nil {
+		log.Fatalf("Error loading fare tables: %v", err)
+	}
+	fareService, err := services.NewFareService(fareTables)
+	if err != nil {
+		log.Fatalf("Invalid fare tables: %v", err)
+	}
+	if fareChart, err := services.LoadFareChart(cfg.FareChartPath); err == nil {
+		if err := fareService.SetFareChart(fareChart); err != nil {
+			log.Fatalf("Invalid fare chart: %v", err)
+		}
+	}
+
+	routeNetwork, err := services.LoadRouteNetwork(cfg.RoutesPath)
+	if err != nil {
+		log.Fatalf("Error loading route network: %v", err)
+	}
+	routeService, err := services.NewRouteService(routeNetwork)
+	if err != nil {
+		log.Fatalf("Invalid route network: %v", err)
+	}
+
+	exportService := services.NewExportService(routeService, fareService, gtfs.ExportOptions{
+		AgencyURL:  cfg.AgencyURL,
+		RoadFactor: cfg.RoadFactor,
+	})
+	feed, err := exportService.GTFSFeed()
+	if err != nil {
+		log.Fatalf("Error building feed: %v", err)
+	}
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		log.Fatalf("Error creating %s: %v", *outPath, err)
+	}
+	if err := gtfs.WriteZip(file, feed); err != nil {
+		file.Close()
+		log.Fatalf("Error writing feed: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatalf("Error writing feed: %v", err)
+	}
+
+	fmt.Printf("Wrote %s: %d agencies, %d stops, %d routes, %d trips, %d fare rules\n",
+		*outPath, len(feed.Agencies), len(feed.Stops), len(feed.Routes), len(feed.Trips), len(feed.FareRules))
+}
+
+// backupFile copies a file to path.bak, keeping its permissions. A missing file is skipped.
+func backupFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+".bak", data, info.Mode().Perm())
+}
+
+// loadLocations reads the stored locations, treating a missing JSON file as empty
+func loadLocations(cfg *config.Config, store storage.LocationStore) ([]models.Location, error) {
+	if storage.Kind(cfg.LocationStore) == storage.KindJSON {
+		if _, err := os.Stat(cfg.LocationsPath); errors.Is(err, os.ErrNotExist) {
+			return []models.Location{}, nil
+		}
+	}
+	return store.LoadLocations()
+}
+
+// linkStops adds a location for every stop that has no matching one and
+// returns the number added
+func linkStops(network *models.RouteNetwork, locations *[]models.Location) int {
+	added := 0
+	for _, stop := range network.Stops {
+		if _, ok := stopLocation(stop, *locations); ok || stop.NameEn == "" {
+			continue
+		}
+		*locations = append(*locations, models.Location{
+			NameEn: stop.NameEn,
+			NameBn: stop.NameBn,
+			Lat:    stop.Lat,
+			Lon:    stop.Lon,
+		})
+		added++
+	}
+	return added
+}
+
+// stopLocation finds the closest location with the stop's name near the stop
+func stopLocation(stop models.Stop, locations []models.Location) (int, bool) {
+	best, bestDistance := -1, float64(stopMatchMeters)
+	for i, location := range locations {
+		if !strings.EqualFold(location.NameEn, stop.NameEn) {
+			continue
+		}
+		if distance := utils.HaversineMeters(stop.Lat, stop.Lon, location.Lat, location.Lon); distance <= bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best, best >= 0
+}
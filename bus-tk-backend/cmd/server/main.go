@@ -1,34 +1,133 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/spectrum/bus-tk-backend/config"
+	"github.com/spectrum/bus-tk-backend/gtfs"
 	"github.com/spectrum/bus-tk-backend/handlers"
+	"github.com/spectrum/bus-tk-backend/nominatim"
+	"github.com/spectrum/bus-tk-backend/osrm"
 	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/storage"
+	"github.com/spectrum/bus-tk-backend/utils"
 )
 
 func main() {
+	// Load configuration
+	cfg := config.Load()
+
 	// Initialize services
-	locationService := services.NewLocationService()
-	fareService := services.NewFareService()
+	locationStore, err := storage.Open(storage.Kind(cfg.LocationStore), cfg.LocationsPath, cfg.LocationsDBPath)
+	if err != nil {
+		log.Fatalf("❌ Error opening location store: %v", err)
+	}
+	defer locationStore.Close()
+	locationService, err := services.NewLocationService(locationStore)
+	if err != nil {
+		log.Fatalf("❌ Error loading locations: %v", err)
+	}
+	if cfg.LocationsWatch > 0 && !locationService.CanWatchLocations() {
+		log.Printf("⚠️ LOCATIONS_WATCH_INTERVAL ignored: the %s location store is only changed through the admin API, so changes to %s are not picked up", cfg.LocationStore, cfg.LocationsPath)
+	} else if cfg.LocationsWatch > 0 {
+		go locationService.WatchLocations(context.Background(), cfg.LocationsWatch)
+		log.Printf("Watching locations for changes every %v", cfg.LocationsWatch)
+	}
+
+	fareTables, err := services.LoadFareTables(cfg.FareTablesPath)
+	if err != nil {
+		log.Fatalf("❌ Error loading fare tables: %v", err)
+	}
+	fareService, err := services.NewFareService(fareTables)
+	if err != nil {
+		log.Fatalf("❌ Invalid fare tables: %v", err)
+	}
+
+	discountCatalog, err := services.LoadDiscountCatalog(cfg.DiscountsPath)
+	if err != nil {
+		log.Fatalf("❌ Error loading discount catalog: %v", err)
+	}
+	if err := fareService.SetDiscounts(discountCatalog); err != nil {
+		log.Fatalf("❌ Invalid discount catalog: %v", err)
+	}
+
+	// The fare chart is optional; without it every quote uses the per-km formula
+	if fareChart, err := services.LoadFareChart(cfg.FareChartPath); err != nil {
+		log.Printf("⚠️ Fare chart not loaded, using per-km fares only: %v", err)
+	} else if err := fareService.SetFareChart(fareChart); err != nil {
+		log.Fatalf("❌ Invalid fare chart: %v", err)
+	} else {
+		log.Printf("Loaded fare chart %s with %d routes", fareChart.Version, len(fareChart.Routes))
+	}
+
+	// Without a holiday calendar, holiday fare adjustments never apply until admins add holidays
+	holidayStore, err := storage.OpenHolidays(locationStore, cfg.HolidaysPath)
+	if err != nil {
+		log.Fatalf("❌ Error opening holiday store: %v", err)
+	}
+	fareService.SetHolidayStore(holidayStore)
+	if holidays, err := holidayStore.LoadHolidays(); err != nil {
+		log.Printf("⚠️ Holiday calendar not loaded: %v", err)
+	} else if err := fareService.SetHolidays(holidays); err != nil {
+		log.Fatalf("❌ Invalid holiday calendar: %v", err)
+	} else {
+		log.Printf("Loaded %d holidays", len(holidays.Holidays))
+	}
+
+	// Routes are optional; without them the route APIs return empty results
+	routeNetwork, err := services.LoadRouteNetwork(cfg.RoutesPath)
+	if err != nil {
+		log.Printf("⚠️ Route network not loaded: %v", err)
+	}
+	routeService, err := services.NewRouteService(routeNetwork)
+	if err != nil {
+		log.Fatalf("❌ Invalid route network: %v", err)
+	}
+	log.Printf("Loaded %d routes, %d stops and %d operators", len(routeNetwork.Routes), len(routeNetwork.Stops), len(routeNetwork.Operators))
+
+	reportService, err := services.NewReportService(cfg.ReportsPath)
+	if err != nil {
+		log.Fatalf("❌ Error loading reports: %v", err)
+	}
+	if cfg.AdminToken == "" {
+		log.Printf("⚠️ ADMIN_TOKEN not set, admin API disabled")
+	}
+
+	distanceService := services.NewDistanceService(osrm.NewClient(cfg.OSRMURL), cfg.RoadFactor)
+	tripPlanner := services.NewTripPlanner(routeService, fareService, cfg.RoadFactor)
+	geocodeService := services.NewGeocodeService(nominatim.NewClient(cfg.NominatimURL))
+	exportService := services.NewExportService(routeService, fareService, gtfs.ExportOptions{
+		AgencyURL:  cfg.AgencyURL,
+		RoadFactor: cfg.RoadFactor,
+	})
 
 	// Initialize handlers
 	locationHandler := handlers.NewLocationHandler(locationService)
-	fareHandler := handlers.NewFareHandler(fareService)
+	fareHandler := handlers.NewFareHandler(fareService, distanceService, locationService, routeService)
+	geocodeHandler := handlers.NewGeocodeHandler(geocodeService)
+	routeHandler := handlers.NewRouteHandler(routeService)
+	tripHandler := handlers.NewTripHandler(tripPlanner, locationService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	reportHandler := handlers.NewReportHandler(reportService, fareService, distanceService, locationService, routeService)
 
 	// Setup routes
-	setupRoutes(locationHandler, fareHandler)
+	setupRoutes(cfg, locationHandler, fareHandler, geocodeHandler, routeHandler, tripHandler, exportHandler, reportHandler)
 
 	// Start server
-	port := "8888"
+	port := cfg.Port
 	log.Printf("🚀 Server starting on port %s", port)
 	log.Printf("📍 Visit: http://localhost:%s", port)
 	log.Printf("📋 API: http://localhost:%s/api/locations", port)
 	log.Printf("🔍 Search: http://localhost:%s/api/locations/search", port)
 	log.Printf("📊 Stats: http://localhost:%s/api/locations/stats", port)
 	log.Printf("💰 API: http://localhost:%s/api/calculate-fare", port)
+	log.Printf("🗺️ Geocode: http://localhost:%s/api/geocode", port)
+	log.Printf("🚌 Routes: http://localhost:%s/api/routes", port)
+	log.Printf("📦 GTFS: http://localhost:%s/api/export/gtfs", port)
 
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatal("❌ Server failed to start:", err)
@@ -36,7 +135,7 @@ func main() {
 }
 
 // setupRoutes configures all the HTTP routes
-func setupRoutes(locationHandler *handlers.LocationHandler, fareHandler *handlers.FareHandler) {
+func setupRoutes(cfg *config.Config, locationHandler *handlers.LocationHandler, fareHandler *handlers.FareHandler, geocodeHandler *handlers.GeocodeHandler, routeHandler *handlers.RouteHandler, tripHandler *handlers.TripHandler, exportHandler *handlers.ExportHandler, reportHandler *handlers.ReportHandler) {
 	// Simple HTTP handler
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Hello from Bus Fare Calculator Backend! v3")
@@ -52,5 +151,35 @@ func setupRoutes(locationHandler *handlers.LocationHandler, fareHandler *handler
 	http.HandleFunc("/api/locations", locationHandler.GetLocations)
 	http.HandleFunc("/api/locations/search", locationHandler.SearchLocations)
 	http.HandleFunc("/api/locations/stats", locationHandler.GetLocationStats)
+	http.HandleFunc("/api/locations/nearest", locationHandler.GetNearestLocations)
+	http.HandleFunc("/api/locations/{id}", locationHandler.GetLocation)
 	http.HandleFunc("/api/calculate-fare", fareHandler.CalculateFare)
+	http.HandleFunc("/api/calculate-fare/multi-leg", fareHandler.CalculateMultiLegFare)
+	http.HandleFunc("/api/calculate-fare/compare", fareHandler.CompareOperatorFares)
+	http.HandleFunc("/api/discounts", fareHandler.GetDiscounts)
+	http.HandleFunc("/api/fare-check", fareHandler.CheckFare)
+	http.HandleFunc("/api/geocode", geocodeHandler.Geocode)
+	http.HandleFunc("/api/reverse-geocode", geocodeHandler.ReverseGeocode)
+	http.HandleFunc("/api/routes", routeHandler.GetRoutes)
+	http.HandleFunc("/api/routes/{id}", routeHandler.GetRoute)
+	http.HandleFunc("/api/stops/{id}/routes", routeHandler.GetStopRoutes)
+	http.HandleFunc("/api/plan-trip", tripHandler.PlanTrip)
+	http.HandleFunc("/api/export/gtfs", exportHandler.ExportGTFS)
+	http.HandleFunc("/api/reports", utils.RateLimit(reportLimiter(cfg), reportHandler.SubmitReport))
+
+	// Admin routes, authenticated with ADMIN_TOKEN
+	http.HandleFunc("/api/admin/locations", utils.RequireAdmin(cfg.AdminToken, locationHandler.CreateLocation))
+	http.HandleFunc("/api/admin/locations/{id}", utils.RequireAdmin(cfg.AdminToken, locationHandler.ModifyLocation))
+	http.HandleFunc("/api/admin/reload", utils.RequireAdmin(cfg.AdminToken, locationHandler.ReloadLocations))
+	http.HandleFunc("/api/admin/reports", utils.RequireAdmin(cfg.AdminToken, reportHandler.GetReports))
+	http.HandleFunc("/api/admin/reports/stats", utils.RequireAdmin(cfg.AdminToken, reportHandler.GetReportStats))
+	http.HandleFunc("/api/admin/holidays", utils.RequireAdmin(cfg.AdminToken, fareHandler.AddHoliday))
+}
+
+// reportLimiter returns the per-client limit on report submissions, or nil when disabled
+func reportLimiter(cfg *config.Config) *utils.RateLimiter {
+	if cfg.ReportsPerHour == 0 {
+		return nil
+	}
+	return utils.NewRateLimiter(cfg.ReportsPerHour, time.Hour)
 }
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// baseDirMarker is a file every backend checkout has, used to recognize the base directory
+const baseDirMarker = "config/fare_tables.json"
+
+// Config holds the runtime settings of the backend
+type Config struct {
+	Port            string
+	BaseDir         string // Directory relative file paths are resolved against
+	FareTablesPath  string
+	FareChartPath   string
+	HolidaysPath    string
+	DiscountsPath   string
+	RoutesPath      string
+	LocationStore   string        // "json" or "bolt"
+	LocationsPath   string        // JSON locations file; seeds an empty bolt store
+	LocationsDBPath string        // bbolt database used by the bolt store
+	LocationsWatch  time.Duration // How often the JSON locations file is checked for changes; 0 disables
+	OSRMURL         string
+	NominatimURL    string
+	RoadFactor      float64 // Ratio of road to great-circle distance used when OSRM is down
+	AgencyURL       string  // agency_url written to exported GTFS feeds
+	ReportsPath     string
+	ReportsPerHour  int    // Reports each client IP may submit per hour; 0 disables the limit
+	AdminToken      string // Bearer token for the admin API; the admin API is disabled when empty
+}
+
+// Load reads the configuration from environment variables, falling back to defaults.
+// Relative file paths are resolved against the base directory, so the server can be
+// started from any working directory.
+func Load() *Config {
+	baseDir := findBaseDir()
+	path := func(key, fallback string) string {
+		value := getEnv(key, fallback)
+		if filepath.IsAbs(value) {
+			return value
+		}
+		return filepath.Join(baseDir, value)
+	}
+
+	return &Config{
+		Port:            getEnv("PORT", "8888"),
+		BaseDir:         baseDir,
+		FareTablesPath:  path("FARE_TABLES_PATH", "config/fare_tables.json"),
+		FareChartPath:   path("FARE_CHART_PATH", "data/fare_chart.json"),
+		HolidaysPath:    path("HOLIDAYS_PATH", "config/holidays.json"),
+		DiscountsPath:   path("DISCOUNTS_PATH", "config/discounts.json"),
+		RoutesPath:      path("ROUTES_PATH", "data/dhaka_routes.json"),
+		LocationStore:   getEnv("LOCATION_STORE", "json"),
+		LocationsPath:   path("LOCATIONS_PATH", "data/dhaka_areas.json"),
+		LocationsDBPath: path("LOCATIONS_DB_PATH", "data/locations.db"),
+		LocationsWatch:  getEnvDuration("LOCATIONS_WATCH_INTERVAL", 0),
+		OSRMURL:         getEnv("OSRM_URL", "http://localhost:5111"),
+		NominatimURL:    getEnv("NOMINATIM_URL", "http://localhost:8111"),
+		RoadFactor:      getEnvFloatAtLeast("ROAD_DETOUR_FACTOR", 1.35, 1),
+		AgencyURL:       getEnv("GTFS_AGENCY_URL", "http://localhost:8888"),
+		ReportsPath:     path("REPORTS_PATH", "data/reports.jsonl"),
+		ReportsPerHour:  getEnvInt("REPORTS_RATE_LIMIT", 10),
+		AdminToken:      getEnv("ADMIN_TOKEN", ""),
+	}
+}
+
+// findBaseDir returns BASE_DIR when set, otherwise the working directory if it is a
+// backend checkout, otherwise the nearest checkout above the executable. It falls
+// back to the working directory.
+func findBaseDir() string {
+	if dir := getEnv("BASE_DIR", ""); dir != "" {
+		if absolute, err := filepath.Abs(dir); err == nil {
+			return absolute
+		}
+		return dir
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+	if isBaseDir(workingDir) {
+		return workingDir
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		if executable, err = filepath.EvalSymlinks(executable); err == nil {
+			for dir := filepath.Dir(executable); ; dir = filepath.Dir(dir) {
+				if isBaseDir(dir) {
+					return dir
+				}
+				if dir == filepath.Dir(dir) {
+					break
+				}
+			}
+		}
+	}
+
+	return workingDir
+}
+
+// isBaseDir reports whether a directory holds the backend configuration
+func isBaseDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, baseDirMarker))
+	return err == nil
+}
+
+// getEnv returns the value of an environment variable or the given default
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt returns the non-negative integer value of an environment variable or the given default
+func getEnvInt(key string, fallback int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("Invalid value %q for %s, using %v", value, key, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvFloat returns the float value of an environment variable or the given default
+func getEnvFloat(key string, fallback float64) float64 {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using %v", value, key, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvFloatAtLeast returns the float value of an environment variable, or the given
+// default when it is unset, invalid or below the minimum
+func getEnvFloatAtLeast(key string, fallback, minimum float64) float64 {
+	value := getEnvFloat(key, fallback)
+	if !(value >= minimum) { // also rejects NaN
+		log.Printf("Invalid value %v for %s (must be at least %v), using %v", value, key, minimum, fallback)
+		return fallback
+	}
+	return value
+}
+
+// getEnvDuration returns the duration value (e.g. "30s") of an environment variable or the given default
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		log.Printf("Invalid value %q for %s, using %v", value, key, fallback)
+		return fallback
+	}
+	return parsed
+}
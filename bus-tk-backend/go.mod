@@ -1,3 +1,7 @@
 module github.com/spectrum/bus-tk-backend
 
 go 1.22.2
+
+require go.etcd.io/bbolt v1.3.11
+
+require golang.org/x/sys v0.22.0 // indirect
@@ -0,0 +1,277 @@
+package gtfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// currencyBDT is the only fare currency the backend understands
+const currencyBDT = "BDT"
+
+// languageBengali is the translations.txt language of Bengali names
+const languageBengali = "bn"
+
+// ConvertOptions controls how a feed is mapped onto the backend's data
+type ConvertOptions struct {
+	BusType      string // Bus type given to every imported route; GTFS has no AC flag
+	ChartVersion string // Version recorded in the generated fare chart
+}
+
+// ToNetwork converts a feed into a route network and a stop-to-stop fare chart.
+// Each bus route takes the stop pattern of its longest trip. Records with
+// broken references are skipped; run Validate first to report them.
+func ToNetwork(feed *Feed, options ConvertOptions) (models.RouteNetwork, *models.FareChart) {
+	if options.BusType == "" {
+		options.BusType = string(models.BusTypeNonAC)
+	}
+
+	network := models.RouteNetwork{
+		Operators: []models.Operator{},
+		Stops:     []models.Stop{},
+		Routes:    []models.Route{},
+	}
+
+	// Agencies become operators; a feed with one agency may leave agency_id empty
+	operatorIDs := make(map[string]string)
+	for _, agency := range feed.Agencies {
+		if _, exists := operatorIDs[agency.ID]; exists {
+			continue
+		}
+		id := agency.ID
+		if id == "" {
+			id = idFromName(agency.Name)
+		}
+		operatorIDs[agency.ID] = id
+		network.Operators = append(network.Operators, models.Operator{ID: id, NameEn: agency.Name})
+	}
+	if len(feed.Agencies) == 1 {
+		operatorIDs[""] = network.Operators[0].ID
+	}
+
+	stops := make(map[string]Stop)
+	for _, stop := range feed.Stops {
+		if _, exists := stops[stop.ID]; exists || stop.LocationType != 0 || !validCoordinates(stop.Lat, stop.Lon) {
+			continue
+		}
+		stops[stop.ID] = stop
+	}
+
+	patterns := tripPatterns(feed, stops)
+
+	chart := &models.FareChart{Version: options.ChartVersion, Routes: []models.FareChartRoute{}}
+	fares := newFareMatcher(feed)
+	usedStops := make(map[string]bool)
+	seenRoutes := make(map[string]bool)
+	for _, route := range feed.Routes {
+		operatorID, ok := operatorIDs[route.AgencyID]
+		pattern := patterns[route.ID]
+		if !ok || seenRoutes[route.ID] || !isBusRouteType(route.Type) || len(pattern) < 2 {
+			continue
+		}
+		seenRoutes[route.ID] = true
+
+		network.Routes = append(network.Routes, models.Route{
+			ID:         route.ID,
+			OperatorID: operatorID,
+			NameEn:     routeName(route),
+			BusType:    options.BusType,
+			StopIDs:    pattern,
+		})
+		for _, stopID := range pattern {
+			usedStops[stopID] = true
+		}
+
+		if entries := fares.chartEntries(route.ID, pattern, stops); len(entries) > 0 {
+			chart.Routes = append(chart.Routes, models.FareChartRoute{RouteID: route.ID, Fares: entries})
+		}
+	}
+
+	// Keep stops in feed order, dropping those no imported route serves. Stops
+	// without a Bengali translation take their English name, as both are required.
+	namesBn := stopNamesBn(feed)
+	for _, stop := range feed.Stops {
+		if usedStops[stop.ID] {
+			delete(usedStops, stop.ID)
+			network.Stops = append(network.Stops, models.Stop{
+				ID:     stop.ID,
+				NameEn: stop.Name,
+				NameBn: firstNonEmpty(namesBn[stop.ID], stop.Name),
+				Lat:    stop.Lat,
+				Lon:    stop.Lon,
+			})
+		}
+	}
+
+	return network, chart
+}
+
+// stopNamesBn returns the Bengali stop names from translations.txt by stop ID.
+// A translation may name the stop by ID or by its original stop_name.
+func stopNamesBn(feed *Feed) map[string]string {
+	byID := make(map[string]string)
+	byName := make(map[string]string)
+	for _, translation := range feed.Translations {
+		if translation.TableName != "stops" || translation.FieldName != "stop_name" || !isBengali(translation.Language) {
+			continue
+		}
+		if translation.RecordID != "" {
+			byID[translation.RecordID] = translation.Translation
+		} else if translation.FieldValue != "" {
+			byName[translation.FieldValue] = translation.Translation
+		}
+	}
+
+	names := make(map[string]string)
+	for _, stop := range feed.Stops {
+		if name := firstNonEmpty(byID[stop.ID], byName[stop.Name]); name != "" {
+			names[stop.ID] = name
+		}
+	}
+	return names
+}
+
+// isBengali reports whether a language tag is Bengali, e.g. "bn" or "bn-BD"
+func isBengali(language string) bool {
+	language = strings.ToLower(language)
+	return language == languageBengali || strings.HasPrefix(language, languageBengali+"-")
+}
+
+// tripPatterns returns, for each route, the ordered stops of its longest trip
+func tripPatterns(feed *Feed, stops map[string]Stop) map[string][]string {
+	tripRoutes := make(map[string]string)
+	for _, trip := range feed.Trips {
+		if _, exists := tripRoutes[trip.ID]; !exists {
+			tripRoutes[trip.ID] = trip.RouteID
+		}
+	}
+
+	stopTimes := make(map[string][]StopTime)
+	for _, stopTime := range feed.StopTimes {
+		if _, ok := stops[stopTime.StopID]; !ok {
+			continue
+		}
+		if _, ok := tripRoutes[stopTime.TripID]; ok {
+			stopTimes[stopTime.TripID] = append(stopTimes[stopTime.TripID], stopTime)
+		}
+	}
+
+	patterns := make(map[string][]string)
+	for _, trip := range feed.Trips {
+		times := stopTimes[trip.ID]
+		sort.SliceStable(times, func(i, j int) bool {
+			return times[i].StopSequence < times[j].StopSequence
+		})
+
+		var pattern []string
+		for _, stopTime := range times {
+			if len(pattern) == 0 || pattern[len(pattern)-1] != stopTime.StopID {
+				pattern = append(pattern, stopTime.StopID)
+			}
+		}
+		if len(pattern) > len(patterns[trip.RouteID]) {
+			patterns[trip.RouteID] = pattern
+		}
+	}
+
+	return patterns
+}
+
+// fareMatcher applies GTFS fare rules to stop pairs
+type fareMatcher struct {
+	prices map[string]float64 // BDT fare attributes by fare ID
+	rules  []FareRule
+}
+
+// newFareMatcher keeps the fare rules that refer to BDT fares. Free fares are
+// dropped because chart fares must be positive.
+func newFareMatcher(feed *Feed) *fareMatcher {
+	matcher := &fareMatcher{prices: make(map[string]float64)}
+	for _, fare := range feed.FareAttributes {
+		if _, exists := matcher.prices[fare.ID]; exists || fare.Price <= 0 || !strings.EqualFold(fare.CurrencyType, currencyBDT) {
+			continue
+		}
+		matcher.prices[fare.ID] = fare.Price
+	}
+	for _, rule := range feed.FareRules {
+		if _, ok := matcher.prices[rule.FareID]; ok {
+			matcher.rules = append(matcher.rules, rule)
+		}
+	}
+	return matcher
+}
+
+// chartEntries prices every boarding/alighting pair along a route pattern
+func (m *fareMatcher) chartEntries(routeID string, pattern []string, stops map[string]Stop) []models.FareChartEntry {
+	if len(m.rules) == 0 {
+		return nil
+	}
+
+	var entries []models.FareChartEntry
+	for i := 0; i < len(pattern); i++ {
+		zones := make(map[string]bool)
+		for j := i; j < len(pattern); j++ {
+			zones[stops[pattern[j]].ZoneID] = true
+			if j == i {
+				continue
+			}
+			fare, ok := m.fare(routeID, stops[pattern[i]].ZoneID, stops[pattern[j]].ZoneID, zones)
+			if ok {
+				entries = append(entries, models.FareChartEntry{From: pattern[i], To: pattern[j], Fare: fare})
+			}
+		}
+	}
+	return entries
+}
+
+// fare finds the price of a ride. Among matching rules the most specific one
+// wins, then the cheapest. A contains_id rule matches when the ride passes
+// through that zone.
+func (m *fareMatcher) fare(routeID, origin, destination string, zones map[string]bool) (float64, bool) {
+	best, bestSpecificity, found := 0.0, -1, false
+	for _, rule := range m.rules {
+		if (rule.RouteID != "" && rule.RouteID != routeID) ||
+			(rule.OriginID != "" && rule.OriginID != origin) ||
+			(rule.DestinationID != "" && rule.DestinationID != destination) ||
+			(rule.ContainsID != "" && !zones[rule.ContainsID]) {
+			continue
+		}
+
+		specificity := 0
+		for _, field := range []string{rule.RouteID, rule.OriginID, rule.DestinationID, rule.ContainsID} {
+			if field != "" {
+				specificity++
+			}
+		}
+		price := m.prices[rule.FareID]
+		if specificity > bestSpecificity || (specificity == bestSpecificity && price < best) {
+			best, bestSpecificity, found = price, specificity, true
+		}
+	}
+	return best, found
+}
+
+// routeName picks the most descriptive route name
+func routeName(route Route) string {
+	switch {
+	case route.LongName != "" && route.ShortName != "":
+		return fmt.Sprintf("%s (%s)", route.LongName, route.ShortName)
+	case route.LongName != "":
+		return route.LongName
+	case route.ShortName != "":
+		return route.ShortName
+	default:
+		return route.ID
+	}
+}
+
+// idFromName derives an identifier for records that have none
+func idFromName(name string) string {
+	id := strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if id == "" {
+		return "agency"
+	}
+	return id
+}
@@ -0,0 +1,153 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// testNetwork returns two operators, three served stops and one unserved stop
+func testNetwork() models.RouteNetwork {
+	return models.RouteNetwork{
+		Operators: []models.Operator{
+			{ID: "op-1", NameEn: "Dhaka Transit"},
+			{ID: "op-2", NameEn: "City Link"},
+		},
+		Stops: []models.Stop{
+			{ID: "gabtoli", NameEn: "Gabtoli", NameBn: "গাবতলী", Lat: 23.7838, Lon: 90.3440},
+			{ID: "farmgate", NameEn: "Farmgate", NameBn: "ফার্মগেট", Lat: 23.7580, Lon: 90.3900},
+			{ID: "motijheel", NameEn: "Motijheel", NameBn: "মতিঝিল", Lat: 23.7330, Lon: 90.4170},
+			{ID: "depot", NameEn: "Depot", NameBn: "ডিপো", Lat: 23.7000, Lon: 90.4000},
+		},
+		Routes: []models.Route{
+			{ID: "r1", OperatorID: "op-1", NameEn: "Gabtoli–Motijheel", BusType: "nonAC", StopIDs: []string{"gabtoli", "farmgate", "motijheel"}},
+			{ID: "r2", OperatorID: "op-2", NameEn: "Farmgate–Motijheel", BusType: "nonAC", StopIDs: []string{"farmgate", "motijheel"}},
+		},
+	}
+}
+
+// testChart prices every stop pair of both test routes
+func testChart() *models.FareChart {
+	return &models.FareChart{
+		Version: "round-trip",
+		Routes: []models.FareChartRoute{
+			{RouteID: "r1", Fares: []models.FareChartEntry{
+				{From: "gabtoli", To: "farmgate", Fare: 20},
+				{From: "gabtoli", To: "motijheel", Fare: 32.5},
+				{From: "farmgate", To: "motijheel", Fare: 20},
+			}},
+			{RouteID: "r2", Fares: []models.FareChartEntry{
+				{From: "farmgate", To: "motijheel", Fare: 25},
+			}},
+		},
+	}
+}
+
+// roundTrip writes a feed as a zip archive and reads it back
+func roundTrip(t *testing.T, feed *Feed) *Feed {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := WriteZip(&buffer, feed); err != nil {
+		t.Fatalf("WriteZip: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	read, issues, err := Read(archive)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(issues) > 0 {
+		t.Errorf("Read reported issues: %v", issues)
+	}
+	return read
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	network := testNetwork()
+	feed := FromNetwork(network, testChart(), ExportOptions{
+		AgencyURL: "https://example.com",
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	read := roundTrip(t, feed)
+	if !reflect.DeepEqual(read, feed) {
+		t.Errorf("feed changed in the zip round trip:\nwrote %+v\nread  %+v", feed, read)
+	}
+	if issues := Validate(read); len(issues) > 0 {
+		t.Errorf("Validate reported issues: %v", issues)
+	}
+
+	imported, chart := ToNetwork(read, ConvertOptions{BusType: "nonAC", ChartVersion: "round-trip"})
+
+	// The unserved depot is not exported; everything else comes back unchanged
+	wantStops := network.Stops[:3]
+	if !reflect.DeepEqual(imported.Stops, wantStops) {
+		t.Errorf("stops = %+v, want %+v", imported.Stops, wantStops)
+	}
+	if !reflect.DeepEqual(imported.Operators, network.Operators) {
+		t.Errorf("operators = %+v, want %+v", imported.Operators, network.Operators)
+	}
+	if !reflect.DeepEqual(imported.Routes, network.Routes) {
+		t.Errorf("routes = %+v, want %+v", imported.Routes, network.Routes)
+	}
+	if !reflect.DeepEqual(chart, testChart()) {
+		t.Errorf("chart = %+v, want %+v", chart, testChart())
+	}
+}
+
+func TestToNetworkBengaliNames(t *testing.T) {
+	feed := FromNetwork(testNetwork(), nil, ExportOptions{AgencyURL: "https://example.com"})
+
+	// Translations may name the stop by its original name instead of its ID
+	feed.Translations = []Translation{
+		{TableName: "stops", FieldName: "stop_name", Language: "bn-BD", Translation: "গাবতলী বাস টার্মিনাল", FieldValue: "Gabtoli"},
+		{TableName: "stops", FieldName: "stop_name", Language: "en", Translation: "Farmgate Mor", RecordID: "farmgate"},
+		{TableName: "routes", FieldName: "route_long_name", Language: "bn", Translation: "মতিঝিল রুট", RecordID: "motijheel"},
+	}
+
+	imported, _ := ToNetwork(roundTrip(t, feed), ConvertOptions{})
+	want := map[string]string{
+		"gabtoli":   "গাবতলী বাস টার্মিনাল",
+		"farmgate":  "Farmgate", // Only an English translation
+		"motijheel": "Motijheel",
+	}
+	for _, stop := range imported.Stops {
+		if stop.NameBn != want[stop.ID] {
+			t.Errorf("%s: nameBn = %q, want %q", stop.ID, stop.NameBn, want[stop.ID])
+		}
+	}
+
+	missing := 0
+	for _, issue := range Validate(feed) {
+		if issue.Severity == SeverityWarning {
+			missing++
+		}
+	}
+	if missing != 1 {
+		t.Errorf("got %d warnings, want one about stops without a Bengali name", missing)
+	}
+}
+
+func TestToNetworkSkipsFreeAndForeignFares(t *testing.T) {
+	feed := FromNetwork(testNetwork(), testChart(), ExportOptions{AgencyURL: "https://example.com"})
+	feed.FareAttributes = append(feed.FareAttributes,
+		FareAttribute{ID: "free", Price: 0, CurrencyType: currencyBDT, Transfers: "0"},
+		FareAttribute{ID: "usd", Price: 1, CurrencyType: "USD", Transfers: "0"},
+	)
+	// Both are more specific than nothing, but must never replace a real fare
+	feed.FareRules = append(feed.FareRules,
+		FareRule{FareID: "free", RouteID: "r2", OriginID: "farmgate", DestinationID: "motijheel", ContainsID: "motijheel"},
+		FareRule{FareID: "usd", RouteID: "r2", OriginID: "farmgate", DestinationID: "motijheel", ContainsID: "farmgate"},
+	)
+
+	_, chart := ToNetwork(feed, ConvertOptions{})
+	if !reflect.DeepEqual(chart.Routes, testChart().Routes) {
+		t.Errorf("chart = %+v, want the BDT fares only", chart.Routes)
+	}
+}
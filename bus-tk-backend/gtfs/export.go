@@ -0,0 +1,217 @@
+package gtfs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// Export defaults; the backend has no timetables, so stop times are estimates
+const (
+	defaultTimezone      = "Asia/Dhaka"
+	defaultSpeedKmh      = 15.0 // Typical Dhaka bus speed including traffic
+	defaultDwellSeconds  = 30
+	defaultServiceDays   = 365
+	exportServiceID      = "daily"
+	firstDepartureSecond = 6 * 60 * 60 // 06:00:00
+	busRouteType         = 3
+)
+
+// ExportOptions controls how the backend's data is written as a feed
+type ExportOptions struct {
+	AgencyURL    string    // Required by GTFS; operators have no website of their own
+	Timezone     string    // Defaults to Asia/Dhaka
+	RoadFactor   float64   // Multiplier from straight-line to road distance
+	SpeedKmh     float64   // Average bus speed used to estimate stop times
+	DwellSeconds int       // Time spent at each intermediate stop
+	StartDate    time.Time // First day of the exported service calendar
+	ServiceDays  int       // Length of the service calendar
+}
+
+// FromNetwork converts a route network and a stop-to-stop fare chart into a
+// feed. Every route runs one trip in each direction a day, leaving at 06:00,
+// and every stop is its own fare zone so chart fares map to fare rules.
+func FromNetwork(network models.RouteNetwork, chart *models.FareChart, options ExportOptions) *Feed {
+	if options.Timezone == "" {
+		options.Timezone = defaultTimezone
+	}
+	if options.RoadFactor <= 0 {
+		options.RoadFactor = 1
+	}
+	if options.SpeedKmh <= 0 {
+		options.SpeedKmh = defaultSpeedKmh
+	}
+	if options.DwellSeconds <= 0 {
+		options.DwellSeconds = defaultDwellSeconds
+	}
+	if options.ServiceDays <= 0 {
+		options.ServiceDays = defaultServiceDays
+	}
+
+	feed := &Feed{}
+	for _, operator := range network.Operators {
+		feed.Agencies = append(feed.Agencies, Agency{
+			ID:       operator.ID,
+			Name:     firstNonEmpty(operator.NameEn, operator.NameBn, operator.ID),
+			URL:      options.AgencyURL,
+			Timezone: options.Timezone,
+		})
+	}
+
+	stops := make(map[string]models.Stop, len(network.Stops))
+	for _, stop := range network.Stops {
+		stops[stop.ID] = stop
+	}
+
+	// Only stops served by a route are exported
+	served := make(map[string]bool)
+	for _, route := range network.Routes {
+		feed.Routes = append(feed.Routes, Route{
+			ID:       route.ID,
+			AgencyID: route.OperatorID,
+			LongName: firstNonEmpty(route.NameEn, route.NameBn, route.ID),
+			Desc:     busTypeDescription(route.BusType),
+			Type:     busRouteType,
+		})
+
+		reversed := make([]string, len(route.StopIDs))
+		for i, stopID := range route.StopIDs {
+			reversed[len(reversed)-1-i] = stopID
+			served[stopID] = true
+		}
+		for direction, pattern := range [][]string{route.StopIDs, reversed} {
+			last := stops[pattern[len(pattern)-1]]
+			trip := Trip{
+				ID:          fmt.Sprintf("%s-%d", route.ID, direction),
+				RouteID:     route.ID,
+				ServiceID:   exportServiceID,
+				DirectionID: strconv.Itoa(direction),
+				Headsign:    firstNonEmpty(last.NameEn, last.NameBn),
+			}
+			feed.Trips = append(feed.Trips, trip)
+			feed.StopTimes = append(feed.StopTimes, estimateStopTimes(trip.ID, pattern, stops, options)...)
+		}
+	}
+
+	for _, stop := range network.Stops {
+		if served[stop.ID] {
+			name := firstNonEmpty(stop.NameEn, stop.NameBn, stop.ID)
+			feed.Stops = append(feed.Stops, Stop{
+				ID:     stop.ID,
+				Name:   name,
+				Lat:    stop.Lat,
+				Lon:    stop.Lon,
+				ZoneID: stop.ID,
+			})
+			if stop.NameBn != "" && stop.NameBn != name {
+				feed.Translations = append(feed.Translations, Translation{
+					TableName:   "stops",
+					FieldName:   "stop_name",
+					Language:    languageBengali,
+					Translation: stop.NameBn,
+					RecordID:    stop.ID,
+				})
+			}
+		}
+	}
+
+	start := options.StartDate
+	if start.IsZero() {
+		start = time.Now()
+	}
+	feed.Calendars = []Calendar{{
+		ServiceID: exportServiceID,
+		Days:      [7]bool{true, true, true, true, true, true, true},
+		StartDate: start.Format("20060102"),
+		EndDate:   start.AddDate(0, 0, options.ServiceDays-1).Format("20060102"),
+	}}
+
+	if chart != nil {
+		addFares(feed, chart)
+	}
+
+	return feed
+}
+
+// estimateStopTimes spaces a trip's stops by road distance at the average speed
+func estimateStopTimes(tripID string, pattern []string, stops map[string]models.Stop, options ExportOptions) []StopTime {
+	stopTimes := make([]StopTime, 0, len(pattern))
+	seconds := float64(firstDepartureSecond)
+	for i, stopID := range pattern {
+		arrival := seconds
+		if i > 0 {
+			previous, current := stops[pattern[i-1]], stops[stopID]
+			meters := utils.HaversineMeters(previous.Lat, previous.Lon, current.Lat, current.Lon) * options.RoadFactor
+			arrival = seconds + meters/1000/options.SpeedKmh*3600
+		}
+		departure := arrival
+		if i > 0 && i < len(pattern)-1 {
+			departure += float64(options.DwellSeconds)
+		}
+
+		stopTimes = append(stopTimes, StopTime{
+			TripID:        tripID,
+			StopID:        stopID,
+			StopSequence:  i + 1,
+			ArrivalTime:   formatTime(arrival),
+			DepartureTime: formatTime(departure),
+		})
+		seconds = departure
+	}
+	return stopTimes
+}
+
+// addFares turns chart entries into one fare per distinct price and one rule per stop pair
+func addFares(feed *Feed, chart *models.FareChart) {
+	fareIDs := make(map[float64]string)
+	for _, route := range chart.Routes {
+		for _, entry := range route.Fares {
+			price := math.Round(entry.Fare*100) / 100 // BDT has two decimal places
+			fareID, ok := fareIDs[price]
+			if !ok {
+				fareID = "bdt-" + formatFloat(price)
+				fareIDs[price] = fareID
+				feed.FareAttributes = append(feed.FareAttributes, FareAttribute{
+					ID:           fareID,
+					Price:        price,
+					CurrencyType: currencyBDT,
+					Transfers:    "0", // Every bus is paid separately
+				})
+			}
+			feed.FareRules = append(feed.FareRules, FareRule{
+				FareID:        fareID,
+				RouteID:       route.RouteID,
+				OriginID:      entry.From,
+				DestinationID: entry.To,
+			})
+		}
+	}
+}
+
+// formatTime formats seconds after midnight as HH:MM:SS
+func formatTime(seconds float64) string {
+	total := int(seconds + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// busTypeDescription describes a bus type for route_desc
+func busTypeDescription(busType string) string {
+	if busType == string(models.BusTypeAC) {
+		return "AC bus"
+	}
+	return "Non-AC bus"
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
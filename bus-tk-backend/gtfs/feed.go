@@ -0,0 +1,114 @@
+// Package gtfs reads and writes GTFS static feeds and converts between them and
+// the backend's location, route and fare data.
+package gtfs
+
+// Agency represents a row of agency.txt
+type Agency struct {
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+}
+
+// Stop represents a row of stops.txt
+type Stop struct {
+	ID           string
+	Name         string
+	Lat          float64
+	Lon          float64
+	ZoneID       string
+	LocationType int // 0 or empty is a stop; stations and entrances are not boardable
+}
+
+// Route represents a row of routes.txt
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Desc      string
+	Type      int
+}
+
+// Trip represents a row of trips.txt
+type Trip struct {
+	ID          string
+	RouteID     string
+	ServiceID   string
+	DirectionID string
+	Headsign    string
+}
+
+// StopTime represents a row of stop_times.txt
+type StopTime struct {
+	TripID        string
+	StopID        string
+	StopSequence  int
+	ArrivalTime   string
+	DepartureTime string
+}
+
+// Calendar represents a row of calendar.txt
+type Calendar struct {
+	ServiceID string
+	Days      [7]bool // Monday first, as in the file
+	StartDate string  // YYYYMMDD
+	EndDate   string  // YYYYMMDD
+}
+
+// FareAttribute represents a row of fare_attributes.txt
+type FareAttribute struct {
+	ID            string
+	Price         float64
+	CurrencyType  string
+	PaymentMethod int
+	Transfers     string // Empty means unlimited transfers
+}
+
+// FareRule represents a row of fare_rules.txt
+type FareRule struct {
+	FareID        string
+	RouteID       string
+	OriginID      string // Zone ID
+	DestinationID string // Zone ID
+	ContainsID    string // Zone ID
+}
+
+// Translation represents a row of translations.txt
+type Translation struct {
+	TableName   string
+	FieldName   string
+	Language    string
+	Translation string
+	RecordID    string // ID of the translated record; empty when matched by FieldValue
+	FieldValue  string // Original value to translate wherever it appears
+}
+
+// Feed represents the tables of a GTFS static feed used by the backend
+type Feed struct {
+	Agencies       []Agency
+	Stops          []Stop
+	Routes         []Route
+	Trips          []Trip
+	StopTimes      []StopTime
+	Calendars      []Calendar
+	FareAttributes []FareAttribute
+	FareRules      []FareRule
+	Translations   []Translation
+}
+
+// Severity represents how serious a validation issue is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The record is skipped during conversion
+	SeverityWarning Severity = "warning" // The record is used but may be wrong
+)
+
+// Issue represents a problem found while reading or validating a feed
+type Issue struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"` // 1-based line in the file, header included
+	Message  string   `json:"message"`
+}
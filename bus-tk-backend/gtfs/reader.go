@@ -0,0 +1,371 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Files read from or written to a feed
+const (
+	fileAgency         = "agency.txt"
+	fileStops          = "stops.txt"
+	fileRoutes         = "routes.txt"
+	fileTrips          = "trips.txt"
+	fileStopTimes      = "stop_times.txt"
+	fileCalendar       = "calendar.txt"
+	fileFareAttributes = "fare_attributes.txt"
+	fileFareRules      = "fare_rules.txt"
+	fileTranslations   = "translations.txt"
+)
+
+// calendarDays are the day columns of calendar.txt, in Calendar.Days order
+var calendarDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// requiredFiles must be present for the feed to describe any routes
+var requiredFiles = []string{fileStops, fileRoutes, fileTrips, fileStopTimes}
+
+// row is a CSV record keyed by column name
+type row struct {
+	line   int
+	fields map[string]string
+}
+
+// ReadZip reads a GTFS feed from a zip file. Rows that cannot be parsed are
+// reported as issues and skipped; only unreadable archives return an error.
+func ReadZip(path string) (*Feed, []Issue, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open GTFS feed: %v", err)
+	}
+	defer archive.Close()
+
+	return Read(&archive.Reader)
+}
+
+// Read reads a GTFS feed from an open zip archive
+func Read(archive *zip.Reader) (*Feed, []Issue, error) {
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		// Some producers nest the feed in a folder
+		name := file.Name[strings.LastIndex(file.Name, "/")+1:]
+		files[name] = file
+	}
+
+	reader := &feedReader{files: files, feed: &Feed{}}
+	for _, name := range requiredFiles {
+		if files[name] == nil {
+			reader.issue(SeverityError, name, 0, "required file is missing")
+		}
+	}
+
+	steps := []func() error{
+		reader.readAgencies,
+		reader.readStops,
+		reader.readRoutes,
+		reader.readTrips,
+		reader.readStopTimes,
+		reader.readCalendars,
+		reader.readFareAttributes,
+		reader.readFareRules,
+		reader.readTranslations,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return reader.feed, reader.issues, nil
+}
+
+// feedReader accumulates the parsed tables and the issues found
+type feedReader struct {
+	files  map[string]*zip.File
+	feed   *Feed
+	issues []Issue
+}
+
+// issue records a problem
+func (r *feedReader) issue(severity Severity, file string, line int, format string, args ...interface{}) {
+	r.issues = append(r.issues, Issue{Severity: severity, File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// rows reads a file's records; a missing optional file yields no rows
+func (r *feedReader) rows(name string) ([]row, error) {
+	file := r.files[name]
+	if file == nil {
+		return nil, nil
+	}
+
+	handle, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", name, err)
+	}
+	defer handle.Close()
+
+	reader := csv.NewReader(handle)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s header: %v", name, err)
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+	}
+
+	var rows []row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			r.issue(SeverityError, name, line, "malformed CSV record: %v", err)
+			continue
+		}
+
+		fields := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				fields[column] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row{line: line, fields: fields})
+	}
+	return rows, nil
+}
+
+// float parses a required decimal field
+func (r *feedReader) float(file string, record row, field string) (float64, bool) {
+	value, err := strconv.ParseFloat(record.fields[field], 64)
+	if err != nil {
+		r.issue(SeverityError, file, record.line, "invalid %s %q", field, record.fields[field])
+		return 0, false
+	}
+	return value, true
+}
+
+// integer parses an optional integer field, defaulting to zero
+func (r *feedReader) integer(file string, record row, field string) (int, bool) {
+	if record.fields[field] == "" {
+		return 0, true
+	}
+	value, err := strconv.Atoi(record.fields[field])
+	if err != nil {
+		r.issue(SeverityError, file, record.line, "invalid %s %q", field, record.fields[field])
+		return 0, false
+	}
+	return value, true
+}
+
+// required reports a missing required field
+func (r *feedReader) required(file string, record row, fields ...string) bool {
+	for _, field := range fields {
+		if record.fields[field] == "" {
+			r.issue(SeverityError, file, record.line, "missing %s", field)
+			return false
+		}
+	}
+	return true
+}
+
+func (r *feedReader) readAgencies() error {
+	rows, err := r.rows(fileAgency)
+	for _, record := range rows {
+		if !r.required(fileAgency, record, "agency_name") {
+			continue
+		}
+		r.feed.Agencies = append(r.feed.Agencies, Agency{
+			ID:       record.fields["agency_id"],
+			Name:     record.fields["agency_name"],
+			URL:      record.fields["agency_url"],
+			Timezone: record.fields["agency_timezone"],
+		})
+	}
+	return err
+}
+
+func (r *feedReader) readStops() error {
+	rows, err := r.rows(fileStops)
+	for _, record := range rows {
+		if !r.required(fileStops, record, "stop_id") {
+			continue
+		}
+		locationType, ok := r.integer(fileStops, record, "location_type")
+		if !ok {
+			continue
+		}
+		stop := Stop{
+			ID:           record.fields["stop_id"],
+			Name:         record.fields["stop_name"],
+			ZoneID:       record.fields["zone_id"],
+			LocationType: locationType,
+		}
+		if locationType == 0 {
+			lat, latOK := r.float(fileStops, record, "stop_lat")
+			lon, lonOK := r.float(fileStops, record, "stop_lon")
+			if !latOK || !lonOK {
+				continue
+			}
+			stop.Lat, stop.Lon = lat, lon
+		}
+		r.feed.Stops = append(r.feed.Stops, stop)
+	}
+	return err
+}
+
+func (r *feedReader) readRoutes() error {
+	rows, err := r.rows(fileRoutes)
+	for _, record := range rows {
+		if !r.required(fileRoutes, record, "route_id", "route_type") {
+			continue
+		}
+		routeType, ok := r.integer(fileRoutes, record, "route_type")
+		if !ok {
+			continue
+		}
+		r.feed.Routes = append(r.feed.Routes, Route{
+			ID:        record.fields["route_id"],
+			AgencyID:  record.fields["agency_id"],
+			ShortName: record.fields["route_short_name"],
+			LongName:  record.fields["route_long_name"],
+			Desc:      record.fields["route_desc"],
+			Type:      routeType,
+		})
+	}
+	return err
+}
+
+func (r *feedReader) readTrips() error {
+	rows, err := r.rows(fileTrips)
+	for _, record := range rows {
+		if !r.required(fileTrips, record, "trip_id", "route_id") {
+			continue
+		}
+		r.feed.Trips = append(r.feed.Trips, Trip{
+			ID:          record.fields["trip_id"],
+			RouteID:     record.fields["route_id"],
+			ServiceID:   record.fields["service_id"],
+			DirectionID: record.fields["direction_id"],
+			Headsign:    record.fields["trip_headsign"],
+		})
+	}
+	return err
+}
+
+func (r *feedReader) readStopTimes() error {
+	rows, err := r.rows(fileStopTimes)
+	for _, record := range rows {
+		if !r.required(fileStopTimes, record, "trip_id", "stop_id", "stop_sequence") {
+			continue
+		}
+		sequence, ok := r.integer(fileStopTimes, record, "stop_sequence")
+		if !ok {
+			continue
+		}
+		r.feed.StopTimes = append(r.feed.StopTimes, StopTime{
+			TripID:        record.fields["trip_id"],
+			StopID:        record.fields["stop_id"],
+			StopSequence:  sequence,
+			ArrivalTime:   record.fields["arrival_time"],
+			DepartureTime: record.fields["departure_time"],
+		})
+	}
+	return err
+}
+
+func (r *feedReader) readCalendars() error {
+	rows, err := r.rows(fileCalendar)
+	for _, record := range rows {
+		if !r.required(fileCalendar, record, "service_id", "start_date", "end_date") {
+			continue
+		}
+		calendar := Calendar{
+			ServiceID: record.fields["service_id"],
+			StartDate: record.fields["start_date"],
+			EndDate:   record.fields["end_date"],
+		}
+		valid := true
+		for i, day := range calendarDays {
+			switch record.fields[day] {
+			case "1":
+				calendar.Days[i] = true
+			case "0":
+			default:
+				r.issue(SeverityError, fileCalendar, record.line, "invalid %s %q", day, record.fields[day])
+				valid = false
+			}
+		}
+		if valid {
+			r.feed.Calendars = append(r.feed.Calendars, calendar)
+		}
+	}
+	return err
+}
+
+func (r *feedReader) readFareAttributes() error {
+	rows, err := r.rows(fileFareAttributes)
+	for _, record := range rows {
+		if !r.required(fileFareAttributes, record, "fare_id", "currency_type") {
+			continue
+		}
+		price, ok := r.float(fileFareAttributes, record, "price")
+		if !ok {
+			continue
+		}
+		paymentMethod, ok := r.integer(fileFareAttributes, record, "payment_method")
+		if !ok {
+			continue
+		}
+		r.feed.FareAttributes = append(r.feed.FareAttributes, FareAttribute{
+			ID:            record.fields["fare_id"],
+			Price:         price,
+			CurrencyType:  record.fields["currency_type"],
+			PaymentMethod: paymentMethod,
+			Transfers:     record.fields["transfers"],
+		})
+	}
+	return err
+}
+
+func (r *feedReader) readFareRules() error {
+	rows, err := r.rows(fileFareRules)
+	for _, record := range rows {
+		if !r.required(fileFareRules, record, "fare_id") {
+			continue
+		}
+		r.feed.FareRules = append(r.feed.FareRules, FareRule{
+			FareID:        record.fields["fare_id"],
+			RouteID:       record.fields["route_id"],
+			OriginID:      record.fields["origin_id"],
+			DestinationID: record.fields["destination_id"],
+			ContainsID:    record.fields["contains_id"],
+		})
+	}
+	return err
+}
+
+func (r *feedReader) readTranslations() error {
+	rows, err := r.rows(fileTranslations)
+	for _, record := range rows {
+		if !r.required(fileTranslations, record, "table_name", "field_name", "language", "translation") {
+			continue
+		}
+		r.feed.Translations = append(r.feed.Translations, Translation{
+			TableName:   record.fields["table_name"],
+			FieldName:   record.fields["field_name"],
+			Language:    record.fields["language"],
+			Translation: record.fields["translation"],
+			RecordID:    record.fields["record_id"],
+			FieldValue:  record.fields["field_value"],
+		})
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// zipFeed builds a zip archive from file names and contents
+func zipFeed(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatalf("Write %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	return archive
+}
+
+func TestReadReportsBadRows(t *testing.T) {
+	archive := zipFeed(t, map[string]string{
+		"feed/agency.txt": "agency_name,agency_url,agency_timezone\nDhaka Transit,https://example.com,Asia/Dhaka\n",
+		"feed/stops.txt": "\ufeffstop_id,stop_name,stop_lat,stop_lon\n" +
+			"a, Gabtoli ,23.78,90.34\n" +
+			"b,Farmgate,north,90.39\n" +
+			",Nameless,23.7,90.4\n",
+		"feed/routes.txt":     "route_id,route_short_name,route_type\nr1,1,3\nr2,2,bus\n",
+		"feed/trips.txt":      "route_id,service_id,trip_id\nr1,daily,t1\n",
+		"feed/stop_times.txt": "trip_id,stop_id,stop_sequence\nt1,a,1\nt1,b,second\n",
+		"feed/calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+			"daily,1,1,1,1,1,0,0,20260101,20261231\n" +
+			"weekend,0,0,0,0,0,yes,1,20260101,20261231\n",
+	})
+
+	feed, issues, err := Read(archive)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	// The BOM is stripped from the first column and values are trimmed
+	if len(feed.Stops) != 1 || feed.Stops[0].ID != "a" || feed.Stops[0].Name != "Gabtoli" {
+		t.Errorf("stops = %+v, want only the valid stop a", feed.Stops)
+	}
+	if len(feed.Routes) != 1 || len(feed.StopTimes) != 1 {
+		t.Errorf("got %d routes and %d stop times, want 1 of each", len(feed.Routes), len(feed.StopTimes))
+	}
+	if len(feed.Calendars) != 1 || feed.Calendars[0].Days != [7]bool{true, true, true, true, true, false, false} {
+		t.Errorf("calendars = %+v, want the weekday service only", feed.Calendars)
+	}
+
+	want := []string{
+		"stops.txt:3: invalid stop_lat",
+		"stops.txt:4: missing stop_id",
+		"routes.txt:3: invalid route_type",
+		"stop_times.txt:3: invalid stop_sequence",
+		"calendar.txt:3: invalid saturday",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %v, want %d", issues, len(want))
+	}
+	for i, issue := range issues {
+		if !strings.Contains(issue.String(), want[i]) {
+			t.Errorf("issue %d = %q, want it to mention %q", i, issue.String(), want[i])
+		}
+	}
+}
+
+func TestReadMissingRequiredFiles(t *testing.T) {
+	archive := zipFeed(t, map[string]string{
+		"agency.txt": "agency_name\nDhaka Transit\n",
+		"stops.txt":  "stop_id,stop_lat,stop_lon\na,23.78,90.34\n",
+	})
+
+	_, issues, err := Read(archive)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !HasErrors(issues) || len(issues) != 3 {
+		t.Errorf("issues = %v, want routes, trips and stop times reported missing", issues)
+	}
+}
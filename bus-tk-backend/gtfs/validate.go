@@ -0,0 +1,159 @@
+package gtfs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks a feed for duplicate IDs, broken references and unusable
+// records. Records with error-severity issues are skipped by ToNetwork.
+func Validate(feed *Feed) []Issue {
+	var issues []Issue
+	report := func(severity Severity, file string, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, File: file, Message: fmt.Sprintf(format, args...)})
+	}
+
+	agencies := make(map[string]bool)
+	for _, agency := range feed.Agencies {
+		if agencies[agency.ID] {
+			report(SeverityError, fileAgency, "duplicate agency_id %q", agency.ID)
+		}
+		agencies[agency.ID] = true
+	}
+
+	stops := make(map[string]bool)
+	zones := make(map[string]bool)
+	namesBn := stopNamesBn(feed)
+	withoutBengali := 0
+	for _, stop := range feed.Stops {
+		if stops[stop.ID] {
+			report(SeverityError, fileStops, "duplicate stop_id %q", stop.ID)
+		}
+		stops[stop.ID] = true
+		if stop.ZoneID != "" {
+			zones[stop.ZoneID] = true
+		}
+		if stop.LocationType == 0 && !validCoordinates(stop.Lat, stop.Lon) {
+			report(SeverityError, fileStops, "stop %q has invalid coordinates %v,%v", stop.ID, stop.Lat, stop.Lon)
+		}
+		if stop.LocationType == 0 && stop.Name == "" {
+			report(SeverityWarning, fileStops, "stop %q has no stop_name", stop.ID)
+		}
+		if stop.LocationType == 0 && stop.Name != "" && namesBn[stop.ID] == "" {
+			withoutBengali++
+		}
+	}
+	if withoutBengali > 0 {
+		report(SeverityWarning, fileTranslations, "%d stops have no Bengali (%s) stop_name translation and will use their English name", withoutBengali, languageBengali)
+	}
+
+	routes := make(map[string]bool)
+	for _, route := range feed.Routes {
+		if routes[route.ID] {
+			report(SeverityError, fileRoutes, "duplicate route_id %q", route.ID)
+		}
+		routes[route.ID] = true
+		switch {
+		case route.AgencyID == "" && len(feed.Agencies) > 1:
+			report(SeverityError, fileRoutes, "route %q has no agency_id but the feed has several agencies", route.ID)
+		case route.AgencyID != "" && !agencies[route.AgencyID]:
+			report(SeverityError, fileRoutes, "route %q references unknown agency %q", route.ID, route.AgencyID)
+		}
+		if !isBusRouteType(route.Type) {
+			report(SeverityWarning, fileRoutes, "route %q has non-bus route_type %d and will be skipped", route.ID, route.Type)
+		}
+	}
+
+	trips := make(map[string]bool)
+	for _, trip := range feed.Trips {
+		if trips[trip.ID] {
+			report(SeverityError, fileTrips, "duplicate trip_id %q", trip.ID)
+		}
+		trips[trip.ID] = true
+		if !routes[trip.RouteID] {
+			report(SeverityError, fileTrips, "trip %q references unknown route %q", trip.ID, trip.RouteID)
+		}
+	}
+
+	stopCounts := make(map[string]int)
+	for _, stopTime := range feed.StopTimes {
+		if !trips[stopTime.TripID] {
+			report(SeverityError, fileStopTimes, "stop time references unknown trip %q", stopTime.TripID)
+			continue
+		}
+		if !stops[stopTime.StopID] {
+			report(SeverityError, fileStopTimes, "trip %q references unknown stop %q", stopTime.TripID, stopTime.StopID)
+			continue
+		}
+		stopCounts[stopTime.TripID]++
+	}
+	for _, trip := range feed.Trips {
+		if trips[trip.ID] && stopCounts[trip.ID] < 2 {
+			report(SeverityWarning, fileTrips, "trip %q has fewer than two valid stops", trip.ID)
+		}
+	}
+
+	fares := make(map[string]bool)
+	for _, fare := range feed.FareAttributes {
+		if fares[fare.ID] {
+			report(SeverityError, fileFareAttributes, "duplicate fare_id %q", fare.ID)
+		}
+		fares[fare.ID] = true
+		if !strings.EqualFold(fare.CurrencyType, currencyBDT) {
+			report(SeverityWarning, fileFareAttributes, "fare %q is in %s, only %s fares are imported", fare.ID, fare.CurrencyType, currencyBDT)
+		}
+		if fare.Price < 0 {
+			report(SeverityError, fileFareAttributes, "fare %q has negative price", fare.ID)
+		}
+		if fare.Price == 0 {
+			report(SeverityWarning, fileFareAttributes, "fare %q is free and will not be imported", fare.ID)
+		}
+	}
+
+	for _, rule := range feed.FareRules {
+		if !fares[rule.FareID] {
+			report(SeverityError, fileFareRules, "fare rule references unknown fare %q", rule.FareID)
+		}
+		if rule.RouteID != "" && !routes[rule.RouteID] {
+			report(SeverityError, fileFareRules, "fare rule %q references unknown route %q", rule.FareID, rule.RouteID)
+		}
+		for _, zone := range []string{rule.OriginID, rule.DestinationID, rule.ContainsID} {
+			if zone != "" && !zones[zone] {
+				report(SeverityWarning, fileFareRules, "fare rule %q references zone %q that no stop uses", rule.FareID, zone)
+			}
+		}
+	}
+
+	return issues
+}
+
+// HasErrors reports whether any issue has error severity
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats an issue for command-line output
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s: %s:%d: %s", i.Severity, i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.File, i.Message)
+}
+
+// validCoordinates reports whether a stop position is usable
+func validCoordinates(lat, lon float64) bool {
+	if lat == 0 && lon == 0 {
+		return false
+	}
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// isBusRouteType reports whether a route_type is a bus, including the extended types
+func isBusRouteType(routeType int) bool {
+	return routeType == 3 || (routeType >= 700 && routeType <= 799)
+}
@@ -0,0 +1,99 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteZip writes a feed as a GTFS zip. Optional files are left out when empty.
+func WriteZip(w io.Writer, feed *Feed) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name     string
+		header   []string
+		rows     int
+		record   func(i int) []string
+		optional bool
+	}{
+		{fileAgency, []string{"agency_id", "agency_name", "agency_url", "agency_timezone"}, len(feed.Agencies), func(i int) []string {
+			agency := feed.Agencies[i]
+			return []string{agency.ID, agency.Name, agency.URL, agency.Timezone}
+		}, false},
+		{fileStops, []string{"stop_id", "stop_name", "stop_lat", "stop_lon", "zone_id", "location_type"}, len(feed.Stops), func(i int) []string {
+			stop := feed.Stops[i]
+			return []string{stop.ID, stop.Name, formatFloat(stop.Lat), formatFloat(stop.Lon), stop.ZoneID, strconv.Itoa(stop.LocationType)}
+		}, false},
+		{fileRoutes, []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_desc", "route_type"}, len(feed.Routes), func(i int) []string {
+			route := feed.Routes[i]
+			return []string{route.ID, route.AgencyID, route.ShortName, route.LongName, route.Desc, strconv.Itoa(route.Type)}
+		}, false},
+		{fileTrips, []string{"route_id", "service_id", "trip_id", "trip_headsign", "direction_id"}, len(feed.Trips), func(i int) []string {
+			trip := feed.Trips[i]
+			return []string{trip.RouteID, trip.ServiceID, trip.ID, trip.Headsign, trip.DirectionID}
+		}, false},
+		{fileStopTimes, []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence"}, len(feed.StopTimes), func(i int) []string {
+			stopTime := feed.StopTimes[i]
+			return []string{stopTime.TripID, stopTime.ArrivalTime, stopTime.DepartureTime, stopTime.StopID, strconv.Itoa(stopTime.StopSequence)}
+		}, false},
+		{fileCalendar, append(append([]string{"service_id"}, calendarDays...), "start_date", "end_date"), len(feed.Calendars), func(i int) []string {
+			calendar := feed.Calendars[i]
+			record := []string{calendar.ServiceID}
+			for _, runs := range calendar.Days {
+				record = append(record, strconv.Itoa(boolToInt(runs)))
+			}
+			return append(record, calendar.StartDate, calendar.EndDate)
+		}, false},
+		{fileFareAttributes, []string{"fare_id", "price", "currency_type", "payment_method", "transfers"}, len(feed.FareAttributes), func(i int) []string {
+			fare := feed.FareAttributes[i]
+			return []string{fare.ID, strconv.FormatFloat(fare.Price, 'f', 2, 64), fare.CurrencyType, strconv.Itoa(fare.PaymentMethod), fare.Transfers}
+		}, true},
+		{fileFareRules, []string{"fare_id", "route_id", "origin_id", "destination_id", "contains_id"}, len(feed.FareRules), func(i int) []string {
+			rule := feed.FareRules[i]
+			return []string{rule.FareID, rule.RouteID, rule.OriginID, rule.DestinationID, rule.ContainsID}
+		}, true},
+		{fileTranslations, []string{"table_name", "field_name", "language", "translation", "record_id", "field_value"}, len(feed.Translations), func(i int) []string {
+			translation := feed.Translations[i]
+			return []string{translation.TableName, translation.FieldName, translation.Language, translation.Translation, translation.RecordID, translation.FieldValue}
+		}, true},
+	}
+
+	for _, file := range files {
+		if file.optional && file.rows == 0 {
+			continue
+		}
+
+		handle, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %v", file.name, err)
+		}
+		writer := csv.NewWriter(handle)
+		writer.Write(file.header)
+		for i := 0; i < file.rows; i++ {
+			writer.Write(file.record(i))
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write %s: %v", file.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// formatFloat formats a coordinate without trailing zeros
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// boolToInt converts a flag to the 0/1 form GTFS uses
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
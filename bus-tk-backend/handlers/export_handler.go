@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+
+	"github.com/spectrum/bus-tk-backend/gtfs"
+	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// ExportHandler handles data export HTTP requests
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportGTFS handles GET /api/export/gtfs
+func (h *ExportHandler) ExportGTFS(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	feed, err := h.exportService.GTFSFeed()
+	if err != nil {
+		log.Printf("GTFS export failed: %v", err)
+		http.Error(w, "Failed to build GTFS feed", http.StatusInternalServerError)
+		return
+	}
+
+	// Build the zip first so a failure can still be reported as an error
+	var archive bytes.Buffer
+	if err := gtfs.WriteZip(&archive, feed); err != nil {
+		log.Printf("GTFS export failed: %v", err)
+		http.Error(w, "Failed to write GTFS feed", http.StatusInternalServerError)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="bus-tk-gtfs.zip"`)
+
+	w.Write(archive.Bytes())
+}
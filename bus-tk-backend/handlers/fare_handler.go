@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -12,13 +13,19 @@ import (
 
 // FareHandler handles fare-related HTTP requests
 type FareHandler struct {
-	fareService *services.FareService
+	fareService     *services.FareService
+	distanceService *services.DistanceService
+	locationService *services.LocationService
+	routeService    *services.RouteService
 }
 
 // NewFareHandler creates a new fare handler
-func NewFareHandler(fareService *services.FareService) *FareHandler { 
+func NewFareHandler(fareService *services.FareService, distanceService *services.DistanceService, locationService *services.LocationService, routeService *services.RouteService) *FareHandler {
 	return &FareHandler{
-		fareService: fareService,
+		fareService:     fareService,
+		distanceService: distanceService,
+		locationService: locationService,
+		routeService:    routeService,
 	}
 }
 
@@ -46,15 +53,24 @@ func (h *FareHandler) CalculateFare(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get distance between start and end locations
-	distance, err := services.GetDistance(request.StartLocation, request.EndLocation)
+	// Use stored locations for any location IDs
+	if err := h.locationService.ResolveFareLocations(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Price with the route's operator unless one is given
+	if err := h.routeService.ResolveFareOperator(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Resolve the trip distance, estimating it if OSRM is unavailable
+	distance, distanceSource, err := h.distanceService.ResolveDistance(r.Context(), request)
 	if err != nil {
-		fmt.Printf("Distance calculation error: %v\n", err)
-		// Use a default distance if calculation fails
-		distance = 5.0 // Default 5km
-		fmt.Printf("Using default distance: %f km\n", distance)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	fmt.Println("Distance:", distance)
 
 	// Calculate fare using service
 	response, err := h.fareService.CalculateFare(request, distance)
@@ -62,6 +78,137 @@ func (h *FareHandler) CalculateFare(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	response.DistanceSource = string(distanceSource)
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// CalculateMultiLegFare handles POST /api/calculate-fare/multi-leg
+func (h *FareHandler) CalculateMultiLegFare(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var request models.MultiLegFareRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Resolve each leg's locations and distance the same way as a single fare
+	distances := make([]float64, len(request.Legs))
+	distanceSources := make([]models.DistanceSource, len(request.Legs))
+	for i := range request.Legs {
+		if err := h.locationService.ResolveFareLocations(&request.Legs[i]); err != nil {
+			http.Error(w, fmt.Sprintf("leg %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		if err := h.routeService.ResolveFareOperator(&request.Legs[i]); err != nil {
+			http.Error(w, fmt.Sprintf("leg %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		distance, distanceSource, err := h.distanceService.ResolveDistance(r.Context(), request.Legs[i])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("leg %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		distances[i], distanceSources[i] = distance, distanceSource
+	}
+
+	// Calculate fares using service
+	response, err := h.fareService.CalculateMultiLegFare(request, distances)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range response.Legs {
+		response.Legs[i].DistanceSource = string(distanceSources[i])
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// CompareOperatorFares handles POST /api/calculate-fare/compare
+func (h *FareHandler) CompareOperatorFares(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var request models.FareRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	boarding := h.routeService.GetStop(request.BoardingStop)
+	alighting := h.routeService.GetStop(request.AlightingStop)
+	if boarding == nil || alighting == nil {
+		http.Error(w, "boardingStop and alightingStop must be known stop IDs", http.StatusBadRequest)
+		return
+	}
+
+	// Measure between the stops unless a distance is given or every route has a chart fare
+	routes := h.routeService.RoutesBetween(boarding.ID, alighting.ID)
+	distance, distanceSource := request.Distance, models.DistanceSource("")
+	if !h.fareService.HasChartFares(request, routes) {
+		request.StartLocationID, request.EndLocationID = "", ""
+		request.StartLocation = models.Location{NameEn: boarding.NameEn, NameBn: boarding.NameBn, Lat: boarding.Lat, Lon: boarding.Lon}
+		request.EndLocation = models.Location{NameEn: alighting.NameEn, NameBn: alighting.NameBn, Lat: alighting.Lat, Lon: alighting.Lon}
+		var err error
+		distance, distanceSource, err = h.distanceService.ResolveDistance(r.Context(), request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Compare the operators running both stops
+	response, err := h.fareService.CompareOperatorFares(request, distance, routes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range response.Fares {
+		response.Fares[i].Fare.DistanceSource = string(distanceSource)
+	}
 
 	// Set response headers
 	w.Header().Set("Content-Type", "application/json")
@@ -72,3 +219,136 @@ func (h *FareHandler) CalculateFare(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// CheckFare handles POST /api/fare-check
+func (h *FareHandler) CheckFare(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var request models.FareCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Resolve the trip the same way as a fare quote
+	if err := h.locationService.ResolveFareLocations(&request.FareRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.routeService.ResolveFareOperator(&request.FareRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	distance, distanceSource, err := h.distanceService.ResolveDistance(r.Context(), request.FareRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Check the charged amount against the legal fare
+	response, err := h.fareService.CheckFare(request, distance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response.LegalFare.DistanceSource = string(distanceSource)
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetDiscounts handles GET /api/discounts
+func (h *FareHandler) GetDiscounts(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get the discount catalog
+	response := h.fareService.GetDiscounts()
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// AddHoliday handles POST /api/admin/holidays
+func (h *FareHandler) AddHoliday(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var request models.Holiday
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Add the day to the calendar used by holiday fare adjustments
+	holiday, err := h.fareService.AddHoliday(request)
+	if errors.Is(err, services.ErrHolidaysNotSaved) {
+		http.Error(w, "Failed to save holiday calendar", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(holiday); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
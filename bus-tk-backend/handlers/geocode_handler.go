@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spectrum/bus-tk-backend/nominatim"
+	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// GeocodeHandler handles geocoding HTTP requests
+type GeocodeHandler struct {
+	geocodeService *services.GeocodeService
+}
+
+// NewGeocodeHandler creates a new geocode handler
+func NewGeocodeHandler(geocodeService *services.GeocodeService) *GeocodeHandler {
+	return &GeocodeHandler{
+		geocodeService: geocodeService,
+	}
+}
+
+// Geocode handles GET /api/geocode
+func (h *GeocodeHandler) Geocode(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse query parameters
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Missing query parameter q", http.StatusBadRequest)
+		return
+	}
+
+	limit := 10 // Default limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
+			limit = l
+		}
+	}
+
+	// Geocode the query
+	response, err := h.geocodeService.Geocode(r.Context(), query, limit)
+	if err != nil {
+		log.Printf("Geocoding failed: %v", err)
+		http.Error(w, "Geocoding service unavailable", http.StatusBadGateway)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ReverseGeocode handles GET /api/reverse-geocode
+func (h *GeocodeHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse query parameters
+	lat, latErr := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+	if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		http.Error(w, "Invalid or missing lat/lon parameters", http.StatusBadRequest)
+		return
+	}
+
+	// Reverse geocode the coordinates
+	response, err := h.geocodeService.ReverseGeocode(r.Context(), lat, lon)
+	if errors.Is(err, nominatim.ErrNotFound) {
+		http.Error(w, "No place found at these coordinates", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Reverse geocoding failed: %v", err)
+		http.Error(w, "Geocoding service unavailable", http.StatusBadGateway)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/spectrum/bus-tk-backend/models"
 	"github.com/spectrum/bus-tk-backend/services"
 	"github.com/spectrum/bus-tk-backend/utils"
 )
@@ -51,6 +53,40 @@ func (h *LocationHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetLocation handles GET /api/locations/{id}
+func (h *LocationHandler) GetLocation(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Look up location
+	location := h.locationService.GetLocationByID(r.PathValue("id"))
+	if location == nil {
+		http.Error(w, "Location not found", http.StatusNotFound)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(location); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // SearchLocations handles GET /api/locations/search
 func (h *LocationHandler) SearchLocations(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
@@ -139,3 +175,187 @@ func (h *LocationHandler) GetLocationStats(w http.ResponseWriter, r *http.Reques
 		return
 	}
 }
+
+// GetNearestLocations handles GET /api/locations/nearest
+func (h *LocationHandler) GetNearestLocations(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse query parameters
+	lat, latErr := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+	if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		http.Error(w, "Invalid or missing lat/lon parameters", http.StatusBadRequest)
+		return
+	}
+
+	// Parse k parameter
+	k := 5 // Default number of results
+	if kStr := r.URL.Query().Get("k"); kStr != "" {
+		if parsed, err := strconv.Atoi(kStr); err == nil && parsed > 0 && parsed <= 50 {
+			k = parsed
+		}
+	}
+
+	// Find nearest locations
+	response := h.locationService.NearestLocations(lat, lon, k)
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateLocation handles POST /api/admin/locations
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var request models.Location
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Add the location
+	location, err := h.locationService.CreateLocation(request)
+	if err != nil {
+		writeLocationEditError(w, err)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(location); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ModifyLocation handles PUT and DELETE /api/admin/locations/{id}
+func (h *LocationHandler) ModifyLocation(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "PUT, DELETE, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		// Parse request body
+		var request models.Location
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Replace the location
+		location, err := h.locationService.UpdateLocation(r.PathValue("id"), request)
+		if err != nil {
+			writeLocationEditError(w, err)
+			return
+		}
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		// Encode response
+		if err := json.NewEncoder(w).Encode(location); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	case "DELETE":
+		if err := h.locationService.DeleteLocation(r.PathValue("id")); err != nil {
+			writeLocationEditError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ReloadLocations handles POST /api/admin/reload
+func (h *LocationHandler) ReloadLocations(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Reload the dataset, keeping the current one if the new one is invalid
+	response, err := h.locationService.ReloadLocations()
+	if errors.Is(err, services.ErrLocationsNotSaved) {
+		http.Error(w, "Failed to save locations", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeLocationEditError reports why a location edit failed
+func writeLocationEditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrLocationNotFound):
+		http.Error(w, "Location not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrLocationsNotSaved):
+		http.Error(w, "Failed to save locations", http.StatusInternalServerError)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
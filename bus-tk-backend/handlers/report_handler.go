@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// maxReportBodyBytes limits the size of a submitted report
+const maxReportBodyBytes = 16 << 10
+
+// ReportHandler handles passenger fare report HTTP requests
+type ReportHandler struct {
+	reportService   *services.ReportService
+	fareService     *services.FareService
+	distanceService *services.DistanceService
+	locationService *services.LocationService
+	routeService    *services.RouteService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService *services.ReportService, fareService *services.FareService, distanceService *services.DistanceService, locationService *services.LocationService, routeService *services.RouteService) *ReportHandler {
+	return &ReportHandler{
+		reportService:   reportService,
+		fareService:     fareService,
+		distanceService: distanceService,
+		locationService: locationService,
+		routeService:    routeService,
+	}
+}
+
+// SubmitReport handles POST /api/reports
+func (h *ReportHandler) SubmitReport(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body; reports are small, so larger bodies are refused unread
+	var request models.ReportRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxReportBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reportService.ValidateReport(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Reports are filed against a known route so they can be grouped by route and operator
+	route := h.routeService.GetRoute(request.RouteID)
+	if route == nil {
+		http.Error(w, "routeId must be a known route ID", http.StatusBadRequest)
+		return
+	}
+	if request.BusType == "" {
+		request.BusType = route.BusType
+	}
+	for _, stopID := range []string{request.BoardingStop, request.AlightingStop} {
+		if stopID != "" && !slices.Contains(route.StopIDs, stopID) {
+			http.Error(w, "boardingStop and alightingStop must be stops of the route", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Resolve the trip the same way as a fare check
+	if err := h.locationService.ResolveFareLocations(&request.FareRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.routeService.ResolveFareOperator(&request.FareRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	distance, _, err := h.distanceService.ResolveDistance(r.Context(), request.FareRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Record the legal fare alongside the amount charged
+	check, err := h.fareService.CheckFare(request.FareCheckRequest, distance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := h.reportService.SubmitReport(request, check)
+	if errors.Is(err, services.ErrReportNotSaved) {
+		http.Error(w, "Failed to save report", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetReports handles GET /api/admin/reports
+func (h *ReportHandler) GetReports(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get reports, optionally filtered by route and operator
+	response := h.reportService.GetReports(reportFilter(r))
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetReportStats handles GET /api/admin/reports/stats
+func (h *ReportHandler) GetReportStats(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Count reports, optionally filtered by route and operator
+	response := h.reportService.GetReportStats(reportFilter(r))
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// reportFilter reads the route and operator query parameters
+func reportFilter(r *http.Request) models.ReportFilter {
+	return models.ReportFilter{
+		RouteID:    r.URL.Query().Get("route"),
+		OperatorID: r.URL.Query().Get("operator"),
+	}
+}
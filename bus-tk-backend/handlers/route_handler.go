@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// RouteHandler handles bus route and stop HTTP requests
+type RouteHandler struct {
+	routeService *services.RouteService
+}
+
+// NewRouteHandler creates a new route handler
+func NewRouteHandler(routeService *services.RouteService) *RouteHandler {
+	return &RouteHandler{
+		routeService: routeService,
+	}
+}
+
+// GetRoutes handles GET /api/routes
+func (h *RouteHandler) GetRoutes(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get routes, optionally filtered by operator
+	response := h.routeService.GetRoutes(r.URL.Query().Get("operator"))
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetRoute handles GET /api/routes/{id}
+func (h *RouteHandler) GetRoute(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Look up route
+	route := h.routeService.GetRoute(r.PathValue("id"))
+	if route == nil {
+		http.Error(w, "Route not found", http.StatusNotFound)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(route); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetStopRoutes handles GET /api/stops/{id}/routes
+func (h *RouteHandler) GetStopRoutes(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "GET, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET method
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Look up routes serving the stop
+	response := h.routeService.GetRoutesForStop(r.PathValue("id"))
+	if response == nil {
+		http.Error(w, "Stop not found", http.StatusNotFound)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/services"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// TripHandler handles trip planning HTTP requests
+type TripHandler struct {
+	tripPlanner     *services.TripPlanner
+	locationService *services.LocationService
+}
+
+// NewTripHandler creates a new trip handler
+func NewTripHandler(tripPlanner *services.TripPlanner, locationService *services.LocationService) *TripHandler {
+	return &TripHandler{
+		tripPlanner:     tripPlanner,
+		locationService: locationService,
+	}
+}
+
+// PlanTrip handles POST /api/plan-trip
+func (h *TripHandler) PlanTrip(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	utils.SetCORSHeaders(w, "POST, OPTIONS")
+
+	// Handle CORS preflight
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST method
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var request models.TripPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Use stored locations for any location IDs
+	if err := h.locationService.ResolveLocations(request.StartLocationID, &request.StartLocation, request.EndLocationID, &request.EndLocation); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Plan the trip
+	response, err := h.tripPlanner.Plan(request)
+	if err != nil {
+		log.Printf("Trip planning failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set response headers
+	w.Header().Set("Content-Type", "application/json")
+
+	// Encode response
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
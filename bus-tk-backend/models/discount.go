@@ -0,0 +1,32 @@
+package models
+
+// Discount represents a passenger discount from the discount catalog
+type Discount struct {
+	ID          string   `json:"id"`
+	NameEn      string   `json:"nameEn"` // Shown as discountApplied in fare responses
+	NameBn      string   `json:"nameBn"`
+	Description string   `json:"description,omitempty"` // Eligibility and proof required
+	Percentage  float64  `json:"percentage,omitempty"`  // Taken off the pre-discount fare
+	Amount      float64  `json:"amount,omitempty"`      // Fixed Taka taken off the pre-discount fare
+	MaxDiscount float64  `json:"maxDiscount,omitempty"` // Cap in Taka per trip; 0 means no cap
+	BusTypes    []string `json:"busTypes,omitempty"`    // Bus types the discount is valid on; empty means all
+	Stackable   bool     `json:"stackable"`             // Whether it can be combined with other stackable discounts
+}
+
+// DiscountCatalog represents the on-disk discount catalog
+type DiscountCatalog struct {
+	Discounts []Discount `json:"discounts"`
+}
+
+// AppliedDiscount represents a discount that reduced a fare
+type AppliedDiscount struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"` // Taka taken off
+}
+
+// DiscountsResponse represents the API response for the discount catalog
+type DiscountsResponse struct {
+	Discounts []Discount `json:"discounts"`
+	Total     int        `json:"total"`
+}
@@ -2,21 +2,122 @@ package models
 
 // FareRequest represents the fare calculation request
 type FareRequest struct {
-	StartLocation Location  `json:"startLocation,omitempty"`
-	EndLocation   Location  `json:"endLocation,omitempty"`
-	Distance      float64 `json:"distance,omitempty"`
-	BusType       string  `json:"busType"`
-	DiscountType  string  `json:"discountType"`
+	StartLocation   Location `json:"startLocation,omitempty"`
+	EndLocation     Location `json:"endLocation,omitempty"`
+	StartLocationID string   `json:"startLocationId,omitempty"` // Takes precedence over startLocation
+	EndLocationID   string   `json:"endLocationId,omitempty"`   // Takes precedence over endLocation
+	Distance        float64  `json:"distance,omitempty"`
+	BusType         string   `json:"busType"`
+	DiscountType    string   `json:"discountType"`
+	DiscountTypes   []string `json:"discountTypes,omitempty"` // Further discount IDs to combine with discountType
+	RouteID         string   `json:"routeId,omitempty"`       // Route for chart-based fares
+	BoardingStop    string   `json:"boardingStop,omitempty"`  // Boarding stop for chart-based fares
+	AlightingStop   string   `json:"alightingStop,omitempty"` // Alighting stop for chart-based fares
+	DepartureTime   string   `json:"departureTime,omitempty"` // RFC 3339; without it no time-of-day or holiday adjustments apply
+	OperatorID      string   `json:"operatorId,omitempty"`    // Operator whose fare override applies; taken from routeId when omitted
 }
 
 // FareResponse represents the fare calculation response
 type FareResponse struct {
-	Fare               float64 `json:"fare"`
-	Distance           float64 `json:"distance"`
-	BusType            string  `json:"busType"`
-	BaseRate           float64 `json:"baseRate"`
-	DiscountApplied    string  `json:"discountApplied"`
-	DiscountPercentage float64 `json:"discountPercentage"`
+	Fare                float64             `json:"fare"`
+	FareFormatted       string              `json:"fareFormatted"`   // e.g. "Tk 65"
+	FareFormattedBn     string              `json:"fareFormattedBn"` // e.g. "৬৫ টাকা"
+	Distance            float64             `json:"distance"`
+	BusType             string              `json:"busType"`
+	OperatorID          string              `json:"operatorId,omitempty"`
+	BaseRate            float64             `json:"baseRate"` // Pre-discount fare, adjustments included; see breakdown for the per-km rate
+	DiscountApplied     string              `json:"discountApplied"`
+	DiscountPercentage  float64             `json:"discountPercentage"` // Effective percentage of baseRate taken off
+	Discounts           []AppliedDiscount   `json:"discounts,omitempty"`
+	IneligibleDiscounts []string            `json:"ineligibleDiscounts,omitempty"` // Requested discounts not valid on this bus type
+	FareTableVersion    string              `json:"fareTableVersion"`
+	FareMethod          string              `json:"fareMethod"`
+	DistanceSource      string              `json:"distanceSource,omitempty"`
+	Adjustments         []AppliedAdjustment `json:"adjustments,omitempty"` // Operator, time-of-day and holiday adjustments included in baseRate
+	Breakdown           FareBreakdown       `json:"breakdown"`
+}
+
+// FareBreakdown itemizes how a fare was reached. The item amounts add up to the total.
+type FareBreakdown struct {
+	RatePerKm float64        `json:"ratePerKm,omitempty"` // Regulated rate for the bus type; omitted for chart fares
+	Items     []FareLineItem `json:"items"`
+	Total     float64        `json:"total"`
+}
+
+// FareLineItem represents one step of a fare calculation
+type FareLineItem struct {
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"` // Taka added to the fare; negative for discounts
+}
+
+// FareCheckRequest represents a trip and the fare a passenger was charged for it
+type FareCheckRequest struct {
+	FareRequest
+	AmountCharged float64 `json:"amountCharged"` // Taka actually paid
+}
+
+// FareCheckResponse represents the outcome of checking a charged fare against the legal fare
+type FareCheckResponse struct {
+	LegalFare             FareResponse `json:"legalFare"`
+	AmountCharged         float64      `json:"amountCharged"`
+	Overcharged           bool         `json:"overcharged"`
+	OverchargeAmount      float64      `json:"overchargeAmount"` // Zero unless overcharged
+	OverchargeFormatted   string       `json:"overchargeFormatted"`
+	OverchargeFormattedBn string       `json:"overchargeFormattedBn"`
+	Regulation            string       `json:"regulation,omitempty"` // Regulation the legal fare is based on
+}
+
+// OperatorFare represents one operator's fare in a fare comparison
+type OperatorFare struct {
+	Operator  Operator     `json:"operator"`
+	RouteID   string       `json:"routeId"`
+	RouteName string       `json:"routeName"`
+	Fare      FareResponse `json:"fare"`
+}
+
+// FareComparisonResponse represents the fares of every operator serving a stop pair
+type FareComparisonResponse struct {
+	BoardingStop  string         `json:"boardingStop"`
+	AlightingStop string         `json:"alightingStop"`
+	Fares         []OperatorFare `json:"fares"` // Cheapest first
+	Total         int            `json:"total"`
+}
+
+// AppliedAdjustment represents a fare adjustment that changed a quote
+type AppliedAdjustment struct {
+	Name      string  `json:"name"`
+	Holiday   string  `json:"holiday,omitempty"`   // Holiday that triggered the adjustment
+	Amount    float64 `json:"amount"`              // Taka added to the pre-discount fare
+	Regulated bool    `json:"regulated,omitempty"` // Part of the fare table's regulation; operator overrides never are
+}
+
+// MultiLegFareRequest represents a fare request for a journey made of several bus legs
+type MultiLegFareRequest struct {
+	Legs          []FareRequest `json:"legs"`
+	DiscountType  string        `json:"discountType"`            // Applied to every leg
+	DiscountTypes []string      `json:"discountTypes,omitempty"` // Further discounts applied to every leg
+	DepartureTime string        `json:"departureTime,omitempty"` // RFC 3339; used for legs without their own
+}
+
+// LegFare represents the fare of one leg of a multi-leg journey
+type LegFare struct {
+	FareResponse
+	TransferRule     string  `json:"transferRule,omitempty"`     // Transfer rule applied to this leg
+	TransferDiscount float64 `json:"transferDiscount,omitempty"` // Taka taken off the pre-discount fare by the transfer rule
+}
+
+// MultiLegFareResponse represents the fares of a multi-leg journey
+type MultiLegFareResponse struct {
+	Legs                 []LegFare `json:"legs"`
+	TotalFare            float64   `json:"totalFare"`
+	TotalFareFormatted   string    `json:"totalFareFormatted"`
+	TotalFareFormattedBn string    `json:"totalFareFormattedBn"`
+	TotalBaseFare        float64   `json:"totalBaseFare"`    // Sum of the legs' pre-discount fares
+	TransferDiscount     float64   `json:"transferDiscount"` // Total taken off by transfer rules
+	DiscountApplied      string    `json:"discountApplied"`
+	DiscountPercentage   float64   `json:"discountPercentage"`
+	FareTableVersion     string    `json:"fareTableVersion"`
 }
 
 // BusType represents the type of bus
@@ -27,11 +128,39 @@ const (
 	BusTypeAC    BusType = "AC"
 )
 
-// DiscountType represents the type of discount
+// FareMethod represents how a fare was calculated
+type FareMethod string
+
+const (
+	FareMethodChart FareMethod = "chart" // Looked up from the BRTA stop-to-stop fare chart
+	FareMethodPerKm FareMethod = "perKm" // Distance multiplied by the per-km rate
+)
+
+// FareLineItemType represents the kind of step a fare line item records
+type FareLineItemType string
+
+const (
+	FareLineItemDistanceCharge FareLineItemType = "distanceCharge" // Distance multiplied by the per-km rate
+	FareLineItemChartFare      FareLineItemType = "chartFare"      // Fare from the BRTA fare chart
+	FareLineItemMinimumFare    FareLineItemType = "minimumFare"    // Top-up to a minimum fare
+	FareLineItemAdjustment     FareLineItemType = "adjustment"     // Operator, time-of-day or holiday adjustment
+	FareLineItemTransfer       FareLineItemType = "transfer"       // Transfer rule on a multi-leg journey
+	FareLineItemDiscount       FareLineItemType = "discount"       // Passenger discount
+	FareLineItemRounding       FareLineItemType = "rounding"       // Rounding policy of the fare table
+)
+
+// DistanceSource represents where the trip distance came from
+type DistanceSource string
+
+const (
+	DistanceSourceOSRM      DistanceSource = "osrm"      // Road distance from OSRM
+	DistanceSourceEstimated DistanceSource = "estimated" // Great-circle estimate, OSRM was unavailable
+	DistanceSourceUser      DistanceSource = "user"      // Distance entered by the user
+)
+
+// DiscountType represents a discount ID. Discounts other than none come from the discount catalog.
 type DiscountType string
 
 const (
-	DiscountTypeNone    DiscountType = "none"
-	DiscountTypeStudent DiscountType = "student"
-	DiscountTypePass    DiscountType = "pass"
+	DiscountTypeNone DiscountType = "none"
 )
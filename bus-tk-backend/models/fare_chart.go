@@ -0,0 +1,20 @@
+package models
+
+// FareChart represents an imported BRTA stop-to-stop fare chart
+type FareChart struct {
+	Version string           `json:"version"`
+	Routes  []FareChartRoute `json:"routes"`
+}
+
+// FareChartRoute holds the chart fares for a single route
+type FareChartRoute struct {
+	RouteID string           `json:"routeId"`
+	Fares   []FareChartEntry `json:"fares"`
+}
+
+// FareChartEntry represents the fare between a boarding and an alighting stop
+type FareChartEntry struct {
+	From string  `json:"from"` // Boarding stop
+	To   string  `json:"to"`   // Alighting stop
+	Fare float64 `json:"fare"`
+}
@@ -0,0 +1,69 @@
+package models
+
+// FareTable represents a versioned set of per-km fare rules
+type FareTable struct {
+	Version                  string             `json:"version"`
+	EffectiveFrom            string             `json:"effectiveFrom"`        // Date the table takes effect (YYYY-MM-DD, Dhaka time)
+	Regulation               string             `json:"regulation,omitempty"` // Government notice the rates come from, cited in fare checks
+	Notes                    string             `json:"notes,omitempty"`      // Remarks for maintainers, e.g. why a table has no adjustments
+	RatesPerKm               map[string]float64 `json:"ratesPerKm"`           // Rate per km keyed by bus type
+	MinimumFare              float64            `json:"minimumFare"`
+	MinimumFareAfterDiscount float64            `json:"minimumFareAfterDiscount"`
+	Rounding                 *RoundingPolicy    `json:"rounding,omitempty"`      // Applied to final fares; unrounded when omitted
+	TransferRules            []TransferRule     `json:"transferRules,omitempty"` // Checked in order, the first match applies
+	Adjustments              []FareAdjustment   `json:"adjustments,omitempty"`   // Every matching adjustment applies, in order
+	OperatorOverrides        []OperatorOverride `json:"operatorOverrides,omitempty"`
+}
+
+// OperatorOverride lets an operator charge differently from the regulated fare,
+// e.g. a seating service on a corridor also served by local buses
+type OperatorOverride struct {
+	OperatorID  string             `json:"operatorId"`
+	Name        string             `json:"name"`                  // e.g. "Seating service"
+	RatesPerKm  map[string]float64 `json:"ratesPerKm,omitempty"`  // Own per-km rates by bus type; chart fares still apply
+	MinimumFare float64            `json:"minimumFare,omitempty"` // Own minimum fare used with own rates
+	Multiplier  float64            `json:"multiplier,omitempty"`  // Applied on top of the regulated or own-rate fare
+}
+
+// FareAdjustment changes the pre-discount fare of trips departing under its conditions.
+// Conditions left empty always match.
+type FareAdjustment struct {
+	Name         string   `json:"name"`
+	StartTime    string   `json:"startTime,omitempty"`    // HH:MM, Dhaka time; a window may wrap past midnight
+	EndTime      string   `json:"endTime,omitempty"`      // HH:MM, exclusive
+	Days         []string `json:"days,omitempty"`         // Weekdays in lowercase, e.g. "friday"
+	HolidayTypes []string `json:"holidayTypes,omitempty"` // Only on holidays of these types
+	BusTypes     []string `json:"busTypes,omitempty"`
+	Multiplier   float64  `json:"multiplier,omitempty"` // e.g. 1.25 for a 25% increase
+	Surcharge    float64  `json:"surcharge,omitempty"`  // Taka added after the multiplier
+	Regulated    bool     `json:"regulated,omitempty"`  // Part of the table's regulation, e.g. an official Eid fare
+}
+
+// RoundingMode represents how a fare is rounded to a multiple of the rounding step
+type RoundingMode string
+
+const (
+	RoundingModeNearest RoundingMode = "nearest"
+	RoundingModeCeil    RoundingMode = "ceil"
+	RoundingModeFloor   RoundingMode = "floor"
+)
+
+// RoundingPolicy describes how final fares are rounded to what conductors collect
+type RoundingPolicy struct {
+	Mode string  `json:"mode"` // "nearest", "ceil" or "floor"
+	Step float64 `json:"step"` // Taka, e.g. 1 or 5
+}
+
+// TransferRule reduces the fare of a bus leg boarded straight after another one
+type TransferRule struct {
+	Name               string  `json:"name"`
+	FromBusType        string  `json:"fromBusType,omitempty"`        // Bus type of the previous leg; empty matches any
+	ToBusType          string  `json:"toBusType,omitempty"`          // Bus type of the next leg; empty matches any
+	DiscountPercentage float64 `json:"discountPercentage,omitempty"` // Taken off the next leg's pre-discount fare
+	DiscountAmount     float64 `json:"discountAmount,omitempty"`     // Taka taken off the next leg's pre-discount fare
+}
+
+// FareTableFile represents the on-disk fare table document
+type FareTableFile struct {
+	Tables []FareTable `json:"tables"`
+}
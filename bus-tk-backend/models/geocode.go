@@ -0,0 +1,16 @@
+package models
+
+// GeocodeResult represents an address resolved by the geocoder.
+// The embedded location can be used directly as a fare start or end point.
+type GeocodeResult struct {
+	Location
+	DisplayName string `json:"displayName"`
+	Type        string `json:"type"`
+}
+
+// GeocodeResponse represents the forward geocoding response
+type GeocodeResponse struct {
+	Results []GeocodeResult `json:"results"`
+	Total   int             `json:"total"`
+	Query   string          `json:"query"`
+}
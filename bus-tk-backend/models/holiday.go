@@ -0,0 +1,14 @@
+package models
+
+// Holiday represents a day in the holiday calendar
+type Holiday struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Name   string `json:"name"`
+	NameBn string `json:"nameBn,omitempty"`
+	Type   string `json:"type"` // e.g. "public", "eid" or "hartal"; matched by fare adjustments
+}
+
+// HolidayCalendar represents the on-disk holiday calendar
+type HolidayCalendar struct {
+	Holidays []Holiday `json:"holidays"`
+}
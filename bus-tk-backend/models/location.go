@@ -2,6 +2,7 @@ package models
 
 // Location represents a location with multilingual names and coordinates
 type Location struct {
+	ID     string  `json:"id,omitempty"` // Stable identifier persisted in the data file
 	NameEn string  `json:"nameEn"`
 	NameBn string  `json:"nameBn"`
 	Lat    float64 `json:"lat"`
@@ -14,6 +15,13 @@ type LocationsResponse struct {
 	Total     int        `json:"total"`
 }
 
+// LocationReloadResponse represents the result of reloading the location dataset
+type LocationReloadResponse struct {
+	Total       int `json:"total"`       // Locations now loaded
+	Previous    int `json:"previous"`    // Locations loaded before the reload
+	AssignedIDs int `json:"assignedIds"` // New locations given an ID
+}
+
 // SearchRequest represents the search request parameters
 type SearchRequest struct {
 	Query    string `json:"query"`    // Search query (can be English or Bengali)
@@ -23,7 +31,39 @@ type SearchRequest struct {
 
 // SearchResponse represents the search response
 type SearchResponse struct {
-	Locations []Location `json:"locations"`
-	Total     int        `json:"total"`
-	Query     string     `json:"query"`
+	Locations []SearchResult `json:"locations"`
+	Total     int            `json:"total"`
+	Query     string         `json:"query"`
+}
+
+// SearchResult represents a location matched by a search with its relevance score
+type SearchResult struct {
+	Location
+	Score     float64 `json:"score"`     // Relevance from 0 to 1, higher is better
+	MatchType string  `json:"matchType"` // How the location matched the query
+}
+
+// MatchType represents how a search result matched the query
+type MatchType string
+
+const (
+	MatchTypeExact    MatchType = "exact"
+	MatchTypePrefix   MatchType = "prefix"
+	MatchTypeContains MatchType = "contains"
+	MatchTypePhonetic MatchType = "phonetic" // Same pronunciation in Bengali or romanized Bengali
+	MatchTypeFuzzy    MatchType = "fuzzy"    // Spelling variant; shown as "did you mean"
+)
+
+// NearestLocation represents a location with its distance from a point
+type NearestLocation struct {
+	Location
+	DistanceMeters float64 `json:"distanceMeters"`
+}
+
+// NearestResponse represents the nearest-location lookup response
+type NearestResponse struct {
+	Locations []NearestLocation `json:"locations"`
+	Total     int               `json:"total"`
+	Lat       float64           `json:"lat"`
+	Lon       float64           `json:"lon"`
 }
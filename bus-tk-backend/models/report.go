@@ -0,0 +1,57 @@
+package models
+
+// ReportRequest represents a passenger's fare report. The trip fields are those
+// of a fare check; routeId and departureTime are required.
+type ReportRequest struct {
+	FareCheckRequest
+	BusRegistration string `json:"busRegistration"` // e.g. "Dhaka Metro-Ba 11-2345"
+	Note            string `json:"note,omitempty"`
+}
+
+// Report represents a stored passenger fare report
+type Report struct {
+	ID               string  `json:"id"`
+	RouteID          string  `json:"routeId"`
+	OperatorID       string  `json:"operatorId"`
+	BusType          string  `json:"busType"`
+	BusRegistration  string  `json:"busRegistration"`
+	BoardingStop     string  `json:"boardingStop,omitempty"`
+	AlightingStop    string  `json:"alightingStop,omitempty"`
+	TripTime         string  `json:"tripTime"` // RFC 3339, from the required departureTime
+	AmountCharged    float64 `json:"amountCharged"`
+	LegalFare        float64 `json:"legalFare"`
+	Overcharged      bool    `json:"overcharged"`
+	OverchargeAmount float64 `json:"overchargeAmount"`
+	FareTableVersion string  `json:"fareTableVersion"`
+	Note             string  `json:"note,omitempty"`
+	SubmittedAt      string  `json:"submittedAt"` // RFC 3339
+}
+
+// ReportFilter selects reports by route and operator; empty fields match every report
+type ReportFilter struct {
+	RouteID    string
+	OperatorID string
+}
+
+// ReportsResponse represents the API response for a list of reports
+type ReportsResponse struct {
+	Reports []Report `json:"reports"` // Newest first
+	Total   int      `json:"total"`
+}
+
+// ReportCount represents report totals for one route or operator
+type ReportCount struct {
+	ID              string  `json:"id"`
+	Reports         int     `json:"reports"`
+	Overcharged     int     `json:"overcharged"`
+	TotalOvercharge float64 `json:"totalOvercharge"`
+}
+
+// ReportStatsResponse represents the API response for report statistics
+type ReportStatsResponse struct {
+	Reports         int           `json:"reports"`
+	Overcharged     int           `json:"overcharged"`
+	TotalOvercharge float64       `json:"totalOvercharge"`
+	ByRoute         []ReportCount `json:"byRoute"`    // Most reported first
+	ByOperator      []ReportCount `json:"byOperator"` // Most reported first
+}
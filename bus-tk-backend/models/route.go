@@ -0,0 +1,56 @@
+package models
+
+// Operator represents a bus company
+type Operator struct {
+	ID     string `json:"id"`
+	NameEn string `json:"nameEn"`
+	NameBn string `json:"nameBn"`
+}
+
+// Stop represents a bus stop
+type Stop struct {
+	ID         string  `json:"id"`
+	NameEn     string  `json:"nameEn"`
+	NameBn     string  `json:"nameBn"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	LocationID string  `json:"locationId,omitempty"` // Matching entry in the location list, if any
+}
+
+// Route represents a bus service run by an operator over an ordered list of stops.
+// Dhaka routes run both ways over the same stops, so a route may be ridden in either
+// direction: the trip planner boards against the stop order and fare charts list each
+// stop pair once. A one-way service needs its own route for the return trip.
+type Route struct {
+	ID         string   `json:"id"`
+	OperatorID string   `json:"operatorId"`
+	NameEn     string   `json:"nameEn"` // e.g. "Gabtoli–Jatrabari"
+	NameBn     string   `json:"nameBn"`
+	BusType    string   `json:"busType"`
+	StopIDs    []string `json:"stopIds"` // Stops in order from one terminus to the other
+}
+
+// RouteNetwork represents the on-disk route data file
+type RouteNetwork struct {
+	Operators []Operator `json:"operators"`
+	Stops     []Stop     `json:"stops"`
+	Routes    []Route    `json:"routes"`
+}
+
+// RouteSummary represents a route with its operator, as listed by the routes API
+type RouteSummary struct {
+	Route
+	Operator Operator `json:"operator"`
+}
+
+// RouteDetail represents a route with its operator and ordered stops
+type RouteDetail struct {
+	RouteSummary
+	Stops []Stop `json:"stops"`
+}
+
+// RoutesResponse represents the API response for a list of routes
+type RoutesResponse struct {
+	Routes []RouteSummary `json:"routes"`
+	Total  int            `json:"total"`
+}
@@ -0,0 +1,52 @@
+package models
+
+// TripPlanRequest represents a request to plan a bus trip between two locations
+type TripPlanRequest struct {
+	StartLocation   Location `json:"startLocation,omitempty"`
+	EndLocation     Location `json:"endLocation,omitempty"`
+	StartLocationID string   `json:"startLocationId,omitempty"` // Takes precedence over startLocation
+	EndLocationID   string   `json:"endLocationId,omitempty"`   // Takes precedence over endLocation
+	DiscountType    string   `json:"discountType"`
+	DiscountTypes   []string `json:"discountTypes,omitempty"` // Further discounts to combine with discountType
+	DepartureTime   string   `json:"departureTime,omitempty"` // RFC 3339; without it no time-of-day or holiday adjustments apply
+}
+
+// TripLegMode represents how a leg of a trip is travelled
+type TripLegMode string
+
+const (
+	TripLegModeWalk TripLegMode = "walk"
+	TripLegModeBus  TripLegMode = "bus"
+)
+
+// TripLeg represents one walking or bus leg of an itinerary
+type TripLeg struct {
+	Mode           string   `json:"mode"`
+	From           string   `json:"from"` // Name of the start point of the leg
+	To             string   `json:"to"`   // Name of the end point of the leg
+	FromStopID     string   `json:"fromStopId,omitempty"`
+	ToStopID       string   `json:"toStopId,omitempty"`
+	RouteID        string   `json:"routeId,omitempty"`
+	RouteName      string   `json:"routeName,omitempty"`
+	OperatorName   string   `json:"operatorName,omitempty"`
+	BusType        string   `json:"busType,omitempty"`
+	Stops          int      `json:"stops,omitempty"` // Number of stops ridden
+	DistanceMeters float64  `json:"distanceMeters"`
+	Fare           *LegFare `json:"fare,omitempty"`
+}
+
+// Itinerary represents a way to travel between two locations
+type Itinerary struct {
+	Legs             []TripLeg `json:"legs"`
+	Transfers        int       `json:"transfers"`
+	TotalFare        float64   `json:"totalFare"`
+	TransferDiscount float64   `json:"transferDiscount,omitempty"` // Taken off by fare table transfer rules
+	BusDistanceKm    float64   `json:"busDistanceKm"`
+	WalkingMeters    float64   `json:"walkingMeters"`
+}
+
+// TripPlanResponse represents the ranked itineraries for a trip
+type TripPlanResponse struct {
+	Itineraries []Itinerary `json:"itineraries"`
+	Total       int         `json:"total"`
+}
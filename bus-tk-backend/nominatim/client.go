@@ -0,0 +1,113 @@
+package nominatim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when Nominatim has no result for a reverse lookup
+var ErrNotFound = errors.New("nominatim: no place found")
+
+// Client is a typed client for the Nominatim geocoding API
+type Client struct {
+	baseURL      string
+	countryCodes string
+	httpClient   *http.Client
+}
+
+// NewClient creates a new Nominatim client for the given base URL (e.g. http://localhost:8111)
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		countryCodes: "bd",
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Search geocodes a free-form query into matching places
+func (c *Client) Search(ctx context.Context, query string, options *SearchOptions) ([]Place, error) {
+	if options == nil {
+		options = &SearchOptions{}
+	}
+
+	params := c.defaultParams(options.Language)
+	params.Set("q", query)
+	params.Set("countrycodes", c.countryCodes)
+	if options.Limit > 0 {
+		params.Set("limit", strconv.Itoa(options.Limit))
+	}
+
+	var places []Place
+	if err := c.get(ctx, "search", params, &places); err != nil {
+		return nil, err
+	}
+	return places, nil
+}
+
+// Reverse finds the place at the given coordinates
+func (c *Client) Reverse(ctx context.Context, lat, lon float64, language string) (*Place, error) {
+	params := c.defaultParams(language)
+	params.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+
+	// Reverse lookups without a result reply with an error object instead of a place
+	var place struct {
+		Place
+		Error string `json:"error"`
+	}
+	if err := c.get(ctx, "reverse", params, &place); err != nil {
+		return nil, err
+	}
+	if place.Error != "" {
+		return nil, ErrNotFound
+	}
+	return &place.Place, nil
+}
+
+// defaultParams returns the parameters shared by all requests
+func (c *Client) defaultParams(language string) url.Values {
+	params := url.Values{}
+	params.Set("format", "jsonv2")
+	params.Set("addressdetails", "1")
+	params.Set("namedetails", "1")
+	if language != "" {
+		params.Set("accept-language", language)
+	}
+	return params
+}
+
+// get calls a Nominatim endpoint and decodes the reply into response
+func (c *Client) get(ctx context.Context, endpoint string, params url.Values, response interface{}) error {
+	requestURL := fmt.Sprintf("%s/%s?%s", c.baseURL, endpoint, params.Encode())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("nominatim: failed to build request: %v", err)
+	}
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("nominatim: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("nominatim: failed to read response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nominatim: %s returned HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("nominatim: failed to parse %s response: %v", endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package nominatim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a client talking to a test server that serves the given handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL + "/")
+}
+
+func TestSearch(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			t.Errorf("path = %q, want /search", r.URL.Path)
+		}
+		query := r.URL.Query()
+		for key, want := range map[string]string{
+			"q":               "Farmgate",
+			"format":          "jsonv2",
+			"countrycodes":    "bd",
+			"limit":           "2",
+			"accept-language": "bn",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("%s = %q, want %q", key, got, want)
+			}
+		}
+		w.Write([]byte(`[
+			{"place_id": 1, "lat": "23.7561", "lon": "90.3872", "name": "Farmgate",
+			 "namedetails": {"name": "Farmgate", "name:bn": "ফার্মগেট"}},
+			{"place_id": 2, "lat": 23.75, "lon": 90.39, "display_name": "Farmgate Road, Dhaka"}
+		]`))
+	})
+
+	places, err := client.Search(context.Background(), "Farmgate", &SearchOptions{Limit: 2, Language: "bn"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(places) != 2 {
+		t.Fatalf("got %d places, want 2", len(places))
+	}
+	if places[0].Lat != 23.7561 || places[0].Lon != 90.3872 {
+		t.Errorf("string coordinates = %v,%v, want 23.7561,90.3872", places[0].Lat, places[0].Lon)
+	}
+	if places[1].Lat != 23.75 || places[1].Lon != 90.39 {
+		t.Errorf("number coordinates = %v,%v, want 23.75,90.39", places[1].Lat, places[1].Lon)
+	}
+	if name := places[0].LocalName("bn"); name != "ফার্মগেট" {
+		t.Errorf("LocalName(bn) = %q, want ফার্মগেট", name)
+	}
+	if name := places[1].LocalName("bn"); name != "Farmgate Road, Dhaka" {
+		t.Errorf("LocalName fallback = %q, want display name", name)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reverse" {
+			t.Errorf("path = %q, want /reverse", r.URL.Path)
+		}
+		if lat, lon := r.URL.Query().Get("lat"), r.URL.Query().Get("lon"); lat != "23.8103" || lon != "90.4125" {
+			t.Errorf("coordinates = %s,%s, want 23.8103,90.4125", lat, lon)
+		}
+		w.Write([]byte(`{"place_id": 7, "lat": "23.8103", "lon": "90.4125", "name": "Banani"}`))
+	})
+
+	place, err := client.Reverse(context.Background(), 23.8103, 90.4125, "")
+	if err != nil {
+		t.Fatalf("Reverse: %v", err)
+	}
+	if place.PlaceID != 7 || place.Name != "Banani" {
+		t.Errorf("place = %+v, want Banani (7)", place)
+	}
+}
+
+func TestReverseNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": "Unable to geocode"}`))
+	})
+
+	if _, err := client.Reverse(context.Background(), 0, 0, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNon200Response(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	})
+
+	_, err := client.Search(context.Background(), "Mirpur", nil)
+	if err == nil {
+		t.Fatal("Search succeeded on HTTP 503")
+	}
+	if !strings.Contains(err.Error(), "HTTP 503") || !strings.Contains(err.Error(), "overloaded") {
+		t.Errorf("err = %v, want status and body", err)
+	}
+
+	if _, err := client.Reverse(context.Background(), 23.8, 90.4, ""); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Reverse err = %v, want a non-ErrNotFound error", err)
+	}
+}
@@ -0,0 +1,62 @@
+package nominatim
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Degrees is a coordinate value; Nominatim encodes coordinates as JSON strings
+type Degrees float64
+
+// UnmarshalJSON accepts both quoted and plain numbers
+func (d *Degrees) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var value float64
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*d = Degrees(value)
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return err
+	}
+	*d = Degrees(value)
+	return nil
+}
+
+// Place represents a place returned by the search and reverse services
+type Place struct {
+	PlaceID     int64             `json:"place_id"`
+	OSMType     string            `json:"osm_type"`
+	OSMID       int64             `json:"osm_id"`
+	Lat         Degrees           `json:"lat"`
+	Lon         Degrees           `json:"lon"`
+	Name        string            `json:"name"`
+	DisplayName string            `json:"display_name"`
+	Category    string            `json:"category"`
+	Type        string            `json:"type"`
+	Importance  float64           `json:"importance"`
+	Address     map[string]string `json:"address,omitempty"`
+	NameDetails map[string]string `json:"namedetails,omitempty"` // Names keyed by tag, e.g. "name:bn"
+}
+
+// LocalName returns the place name in the given language, falling back to the default name
+func (p *Place) LocalName(language string) string {
+	if name := p.NameDetails["name:"+language]; name != "" {
+		return name
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.DisplayName
+}
+
+// SearchOptions represents the optional parameters of the search service
+type SearchOptions struct {
+	Limit    int    // Maximum number of results; Nominatim's default when zero
+	Language string // Preferred language for names, e.g. "en" or "bn"
+}
@@ -0,0 +1,181 @@
+package osrm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a typed client for the OSRM HTTP API
+type Client struct {
+	baseURL    string
+	profile    string
+	httpClient *http.Client
+}
+
+// NewClient creates a new OSRM client for the given base URL (e.g. http://localhost:5111)
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		profile:    "driving",
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Route finds the fastest route through the given coordinates
+func (c *Client) Route(ctx context.Context, coordinates []Coordinate, options *RouteOptions) (*RouteResponse, error) {
+	if options == nil {
+		options = &RouteOptions{}
+	}
+
+	params := url.Values{}
+	params.Set("alternatives", strconv.FormatBool(options.Alternatives))
+	params.Set("steps", strconv.FormatBool(options.Steps))
+	params.Set("overview", overviewOrDefault(options.Overview))
+
+	var response RouteResponse
+	if err := c.get(ctx, "route", coordinates, params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Table computes the duration (and optionally distance) matrix between the given coordinates
+func (c *Client) Table(ctx context.Context, coordinates []Coordinate, options *TableOptions) (*TableResponse, error) {
+	if options == nil {
+		options = &TableOptions{}
+	}
+
+	params := url.Values{}
+	if len(options.Sources) > 0 {
+		params.Set("sources", joinInts(options.Sources))
+	}
+	if len(options.Destinations) > 0 {
+		params.Set("destinations", joinInts(options.Destinations))
+	}
+	if options.Distances {
+		params.Set("annotations", "duration,distance")
+	}
+
+	var response TableResponse
+	if err := c.get(ctx, "table", coordinates, params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Nearest snaps a coordinate to the closest points of the road network
+func (c *Client) Nearest(ctx context.Context, coordinate Coordinate, number int) (*NearestResponse, error) {
+	params := url.Values{}
+	if number > 0 {
+		params.Set("number", strconv.Itoa(number))
+	}
+
+	var response NearestResponse
+	if err := c.get(ctx, "nearest", []Coordinate{coordinate}, params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Match snaps a GPS trace to the road network
+func (c *Client) Match(ctx context.Context, coordinates []Coordinate, options *MatchOptions) (*MatchResponse, error) {
+	if options == nil {
+		options = &MatchOptions{}
+	}
+
+	params := url.Values{}
+	params.Set("overview", overviewOrDefault(options.Overview))
+	if len(options.Timestamps) > 0 {
+		values := make([]string, len(options.Timestamps))
+		for i, timestamp := range options.Timestamps {
+			values[i] = strconv.FormatInt(timestamp, 10)
+		}
+		params.Set("timestamps", strings.Join(values, ";"))
+	}
+	if len(options.Radiuses) > 0 {
+		values := make([]string, len(options.Radiuses))
+		for i, radius := range options.Radiuses {
+			values[i] = strconv.FormatFloat(radius, 'f', -1, 64)
+		}
+		params.Set("radiuses", strings.Join(values, ";"))
+	}
+
+	var response MatchResponse
+	if err := c.get(ctx, "match", coordinates, params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// get calls an OSRM service and decodes the reply into response
+func (c *Client) get(ctx context.Context, service string, coordinates []Coordinate, params url.Values, response interface{}) error {
+	if len(coordinates) == 0 {
+		return &Error{Code: "InvalidQuery", Message: "at least one coordinate is required"}
+	}
+
+	requestURL := fmt.Sprintf("%s/%s/v1/%s/%s?%s", c.baseURL, service, c.profile, formatCoordinates(coordinates), params.Encode())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("osrm: failed to build request: %v", err)
+	}
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("osrm: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// OSRM replies with a JSON body carrying code and message for both success and errors
+	var status struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("osrm: failed to read response body: %v", err)
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("osrm: failed to parse response (HTTP %d): %v", resp.StatusCode, err)
+	}
+	if status.Code != "Ok" {
+		return &Error{Code: status.Code, Message: status.Message}
+	}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("osrm: failed to parse %s response: %v", service, err)
+	}
+	return nil
+}
+
+// formatCoordinates encodes coordinates as OSRM's lon,lat;lon,lat path segment
+func formatCoordinates(coordinates []Coordinate) string {
+	parts := make([]string, len(coordinates))
+	for i, coordinate := range coordinates {
+		parts[i] = strconv.FormatFloat(coordinate.Lon, 'f', 6, 64) + "," + strconv.FormatFloat(coordinate.Lat, 'f', 6, 64)
+	}
+	return strings.Join(parts, ";")
+}
+
+// joinInts joins indexes with OSRM's ';' separator
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ";")
+}
+
+// overviewOrDefault returns the overview parameter, defaulting to no geometry
+func overviewOrDefault(overview string) string {
+	if overview == "" {
+		return "false"
+	}
+	return overview
+}
@@ -0,0 +1,125 @@
+package osrm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns a client talking to a test server that serves the given handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL + "/")
+}
+
+func TestRoute(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if want := "/route/v1/driving/90.387200,23.756100;90.412500,23.810300"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		query := r.URL.Query()
+		for key, want := range map[string]string{
+			"alternatives": "false",
+			"steps":        "false",
+			"overview":     "false",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("%s = %q, want %q", key, got, want)
+			}
+		}
+		w.Write([]byte(`{"code": "Ok", "routes": [{"distance": 7340.5, "duration": 912, "legs": [{"distance": 7340.5}]}],
+			"waypoints": [{"name": "Farmgate", "location": [90.3872, 23.7561]}, {"name": "Banani", "location": [90.4125, 23.8103]}]}`))
+	})
+
+	response, err := client.Route(context.Background(), []Coordinate{{Lon: 90.3872, Lat: 23.7561}, {Lon: 90.4125, Lat: 23.8103}}, nil)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if len(response.Routes) != 1 || response.Routes[0].Distance != 7340.5 || len(response.Routes[0].Legs) != 1 {
+		t.Errorf("routes = %+v, want one 7340.5 m route with one leg", response.Routes)
+	}
+	if len(response.Waypoints) != 2 || response.Waypoints[1].Name != "Banani" {
+		t.Errorf("waypoints = %+v, want Farmgate and Banani", response.Waypoints)
+	}
+}
+
+func TestTable(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		for key, want := range map[string]string{
+			"sources":      "0",
+			"destinations": "1;2",
+			"annotations":  "duration,distance",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("%s = %q, want %q", key, got, want)
+			}
+		}
+		w.Write([]byte(`{"code": "Ok", "durations": [[120, null]], "distances": [[1500, null]]}`))
+	})
+
+	coordinates := []Coordinate{{Lon: 90.38, Lat: 23.75}, {Lon: 90.39, Lat: 23.76}, {Lon: 90.40, Lat: 23.77}}
+	response, err := client.Table(context.Background(), coordinates, &TableOptions{Sources: []int{0}, Destinations: []int{1, 2}, Distances: true})
+	if err != nil {
+		t.Fatalf("Table: %v", err)
+	}
+	if len(response.Distances) != 1 || len(response.Distances[0]) != 2 {
+		t.Fatalf("distances = %v, want a 1x2 matrix", response.Distances)
+	}
+	if distance := response.Distances[0][0]; distance == nil || *distance != 1500 {
+		t.Errorf("reachable distance = %v, want 1500", distance)
+	}
+	if distance := response.Distances[0][1]; distance != nil {
+		t.Errorf("unreachable distance = %v, want nil", *distance)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"NoRoute", ErrNoRoute},
+		{"NoSegment", ErrNoSegment},
+		{"InvalidValue", ErrInvalidQuery},
+		{"TooBig", ErrTooBig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code": "` + tt.code + `", "message": "details"}`))
+			})
+
+			_, err := client.Route(context.Background(), []Coordinate{{Lon: 90.38, Lat: 23.75}, {Lon: 90.39, Lat: 23.76}}, nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			var osrmErr *Error
+			if !errors.As(err, &osrmErr) || osrmErr.Code != tt.code || osrmErr.Message != "details" {
+				t.Errorf("err = %#v, want code %s with its message", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+	})
+
+	_, err := client.Nearest(context.Background(), Coordinate{Lon: 90.38, Lat: 23.75}, 1)
+	if err == nil || !strings.Contains(err.Error(), "HTTP 502") {
+		t.Errorf("err = %v, want a parse error with the status", err)
+	}
+
+	if _, err := client.Route(context.Background(), nil, nil); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("err = %v, want ErrInvalidQuery without coordinates", err)
+	}
+}
@@ -0,0 +1,50 @@
+package osrm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors for the OSRM response codes callers usually need to tell apart
+var (
+	ErrInvalidQuery = errors.New("osrm: invalid query")
+	ErrNoSegment    = errors.New("osrm: coordinate could not be snapped to the road network")
+	ErrNoRoute      = errors.New("osrm: no route found")
+	ErrNoTable      = errors.New("osrm: no table found")
+	ErrNoMatch      = errors.New("osrm: no matching found")
+	ErrTooBig       = errors.New("osrm: request too big")
+)
+
+// errorsByCode maps OSRM response codes to their sentinel errors
+var errorsByCode = map[string]error{
+	"InvalidUrl":     ErrInvalidQuery,
+	"InvalidService": ErrInvalidQuery,
+	"InvalidVersion": ErrInvalidQuery,
+	"InvalidOptions": ErrInvalidQuery,
+	"InvalidQuery":   ErrInvalidQuery,
+	"InvalidValue":   ErrInvalidQuery,
+	"NoSegment":      ErrNoSegment,
+	"NoRoute":        ErrNoRoute,
+	"NoTable":        ErrNoTable,
+	"NoMatch":        ErrNoMatch,
+	"TooBig":         ErrTooBig,
+}
+
+// Error represents a non-Ok response returned by OSRM
+type Error struct {
+	Code    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("osrm: %s", e.Code)
+	}
+	return fmt.Sprintf("osrm: %s: %s", e.Code, e.Message)
+}
+
+// Is reports whether the OSRM code maps to the target sentinel error
+func (e *Error) Is(target error) bool {
+	return errorsByCode[e.Code] == target
+}
@@ -0,0 +1,104 @@
+package osrm
+
+// Coordinate represents a WGS84 position in OSRM's lon,lat order
+type Coordinate struct {
+	Lon float64
+	Lat float64
+}
+
+// Waypoint represents an input coordinate snapped to the road network
+type Waypoint struct {
+	Name     string     `json:"name"`
+	Location [2]float64 `json:"location"` // [lon, lat]
+	Distance float64    `json:"distance"` // Distance in meters from the input coordinate
+	Hint     string     `json:"hint"`
+	Nodes    []int64    `json:"nodes,omitempty"` // Only set by the nearest service
+}
+
+// Route represents a route through the input waypoints
+type Route struct {
+	Distance   float64    `json:"distance"` // Meters
+	Duration   float64    `json:"duration"` // Seconds
+	Weight     float64    `json:"weight"`
+	WeightName string     `json:"weight_name"`
+	Geometry   string     `json:"geometry,omitempty"`
+	Legs       []RouteLeg `json:"legs"`
+}
+
+// RouteLeg represents the part of a route between two waypoints
+type RouteLeg struct {
+	Distance float64 `json:"distance"` // Meters
+	Duration float64 `json:"duration"` // Seconds
+	Weight   float64 `json:"weight"`
+	Summary  string  `json:"summary"`
+}
+
+// RouteResponse represents the reply of the route service
+type RouteResponse struct {
+	Code      string     `json:"code"`
+	Message   string     `json:"message,omitempty"`
+	Routes    []Route    `json:"routes"`
+	Waypoints []Waypoint `json:"waypoints"`
+}
+
+// TableResponse represents the reply of the table service.
+// Unreachable pairs are reported as nil entries.
+type TableResponse struct {
+	Code         string       `json:"code"`
+	Message      string       `json:"message,omitempty"`
+	Durations    [][]*float64 `json:"durations,omitempty"` // Seconds
+	Distances    [][]*float64 `json:"distances,omitempty"` // Meters
+	Sources      []Waypoint   `json:"sources"`
+	Destinations []Waypoint   `json:"destinations"`
+}
+
+// NearestResponse represents the reply of the nearest service
+type NearestResponse struct {
+	Code      string     `json:"code"`
+	Message   string     `json:"message,omitempty"`
+	Waypoints []Waypoint `json:"waypoints"`
+}
+
+// Tracepoint represents an input coordinate of a match request.
+// Coordinates that could not be matched are reported as nil tracepoints.
+type Tracepoint struct {
+	Waypoint
+	MatchingsIndex    int `json:"matchings_index"`
+	WaypointIndex     int `json:"waypoint_index"`
+	AlternativesCount int `json:"alternatives_count"`
+}
+
+// Matching represents a route matched to a GPS trace
+type Matching struct {
+	Route
+	Confidence float64 `json:"confidence"`
+}
+
+// MatchResponse represents the reply of the match service
+type MatchResponse struct {
+	Code        string        `json:"code"`
+	Message     string        `json:"message,omitempty"`
+	Tracepoints []*Tracepoint `json:"tracepoints"`
+	Matchings   []Matching    `json:"matchings"`
+}
+
+// RouteOptions represents the optional parameters of the route service
+type RouteOptions struct {
+	Alternatives bool
+	Steps        bool
+	Overview     string // "simplified", "full" or "false" (default)
+}
+
+// TableOptions represents the optional parameters of the table service
+type TableOptions struct {
+	Sources      []int // Indexes into the coordinates; all when empty
+	Destinations []int // Indexes into the coordinates; all when empty
+	Distances    bool  // Also return distances in addition to durations
+}
+
+// MatchOptions represents the optional parameters of the match service
+type MatchOptions struct {
+	Timestamps []int64   // UNIX timestamps, one per coordinate
+	Radiuses   []float64 // GPS accuracy in meters, one per coordinate
+	Overview   string    // "simplified", "full" or "false" (default)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// discountQuote is the outcome of applying a passenger's discounts to a fare
+type discountQuote struct {
+	fare       utils.Poisha
+	topUp      utils.Poisha // Added to reach the minimum fare after discount
+	label      string       // Names of the applied discounts, or "None"
+	percentage float64      // Effective share of the fare taken off
+	applied    []models.AppliedDiscount
+	ineligible []string // Requested discounts not valid on the bus type
+}
+
+// LoadDiscountCatalog reads the discount catalog from a JSON file
+func LoadDiscountCatalog(path string) (*models.DiscountCatalog, error) {
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discount catalog: %v", err)
+	}
+
+	var catalog models.DiscountCatalog
+	if err := json.Unmarshal(jsonData, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse discount catalog: %v", err)
+	}
+
+	return &catalog, nil
+}
+
+// SetDiscounts replaces the discount catalog. Without one only "none" is accepted.
+func (s *FareService) SetDiscounts(catalog *models.DiscountCatalog) error {
+	discounts := make(map[string]models.Discount)
+	for _, discount := range catalog.Discounts {
+		if discount.ID == "" || discount.ID == string(models.DiscountTypeNone) {
+			return fmt.Errorf("discount %q: id must be set and not %q", discount.NameEn, models.DiscountTypeNone)
+		}
+		if _, exists := discounts[discount.ID]; exists {
+			return fmt.Errorf("duplicate discount id %q", discount.ID)
+		}
+		if discount.NameEn == "" {
+			return fmt.Errorf("discount %q: nameEn is required", discount.ID)
+		}
+		if discount.Percentage < 0 || discount.Percentage > 100 || discount.Amount < 0 || discount.MaxDiscount < 0 {
+			return fmt.Errorf("discount %q: percentage must be 0-100 and amounts must not be negative", discount.ID)
+		}
+		if discount.Percentage == 0 && discount.Amount == 0 {
+			return fmt.Errorf("discount %q: percentage or amount is required", discount.ID)
+		}
+		for _, busType := range discount.BusTypes {
+			if !s.knownBusType(busType) {
+				return fmt.Errorf("discount %q: unknown bus type %q", discount.ID, busType)
+			}
+		}
+		discounts[discount.ID] = discount
+	}
+
+	s.discounts = catalog.Discounts
+	s.discountsByID = discounts
+	return nil
+}
+
+// GetDiscounts returns the discount catalog in file order
+func (s *FareService) GetDiscounts() models.DiscountsResponse {
+	discounts := append([]models.Discount{}, s.discounts...)
+	return models.DiscountsResponse{
+		Discounts: discounts,
+		Total:     len(discounts),
+	}
+}
+
+// knownBusType reports whether any fare table has a rate for the bus type
+func (s *FareService) knownBusType(busType string) bool {
+	for _, table := range s.tables {
+		if _, ok := table.RatesPerKm[busType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDiscounts looks up the requested discount IDs and checks they may be combined
+func (s *FareService) resolveDiscounts(discountType string, discountTypes []string) ([]models.Discount, error) {
+	var discounts []models.Discount
+	seen := make(map[string]bool)
+	for _, id := range append([]string{discountType}, discountTypes...) {
+		if id == "" || id == string(models.DiscountTypeNone) || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		discount, ok := s.discountsByID[id]
+		if !ok {
+			return nil, fmt.Errorf("invalid request: unknown discount type %q", id)
+		}
+		discounts = append(discounts, discount)
+	}
+
+	if len(discounts) > 1 {
+		for _, discount := range discounts {
+			if !discount.Stackable {
+				return nil, fmt.Errorf("invalid request: discount %q cannot be combined with other discounts", discount.ID)
+			}
+		}
+	}
+	return discounts, nil
+}
+
+// applyDiscounts takes the discounts valid on the bus type off the pre-discount
+// fare. Each discount is computed on the pre-discount fare and capped separately,
+// then the minimum fare after discount is enforced.
+func (s *FareService) applyDiscounts(table *fareTable, baseFare utils.Poisha, busType string, discounts []models.Discount) discountQuote {
+	quote := discountQuote{label: "None"}
+
+	var total utils.Poisha
+	var names []string
+	for _, discount := range discounts {
+		if len(discount.BusTypes) > 0 && !slices.Contains(discount.BusTypes, busType) {
+			quote.ineligible = append(quote.ineligible, discount.ID)
+			continue
+		}
+
+		amount := baseFare.Percent(discount.Percentage) + utils.TakaToPoisha(discount.Amount)
+		if discount.MaxDiscount > 0 {
+			amount = min(amount, utils.TakaToPoisha(discount.MaxDiscount))
+		}
+		amount = min(amount, baseFare-total)
+
+		total += amount
+		names = append(names, discount.NameEn)
+		quote.applied = append(quote.applied, models.AppliedDiscount{ID: discount.ID, Name: discount.NameEn, Amount: amount.Taka()})
+	}
+
+	if len(names) > 0 {
+		quote.label = strings.Join(names, " + ")
+	}
+	if baseFare > 0 {
+		quote.percentage = math.Round(float64(total)/float64(baseFare)*10000) / 100
+	}
+
+	// Ensure minimum fare after discount
+	quote.fare = max(baseFare-total, utils.TakaToPoisha(table.MinimumFareAfterDiscount))
+	quote.topUp = quote.fare - (baseFare - total)
+	return quote
+}
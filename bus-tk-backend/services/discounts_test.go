@@ -0,0 +1,127 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// testDiscountCatalog returns stackable percentage, capped and fixed-amount
+// discounts, an AC-only discount and one that cannot be combined
+func testDiscountCatalog() *models.DiscountCatalog {
+	return &models.DiscountCatalog{Discounts: []models.Discount{
+		{ID: "student", NameEn: "Student", Percentage: 50, Stackable: true},
+		{ID: "senior", NameEn: "Senior", Percentage: 20, MaxDiscount: 15, Stackable: true},
+		{ID: "disabled", NameEn: "Disabled", Amount: 10, Stackable: true},
+		{ID: "ac-promo", NameEn: "AC promo", Percentage: 25, BusTypes: []string{"AC"}, Stackable: true},
+		{ID: "staff", NameEn: "Staff", Percentage: 100},
+	}}
+}
+
+func TestDiscounts(t *testing.T) {
+	service := newTestFareService(t)
+	if err := service.SetDiscounts(testDiscountCatalog()); err != nil {
+		t.Fatalf("SetDiscounts: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		busType        string
+		discountType   string
+		discountTypes  []string
+		want           float64
+		wantLabel      string
+		wantPercentage float64
+		wantIneligible []string
+	}{
+		{name: "none", discountType: "none", want: 100, wantLabel: "None"},
+		{name: "percentage", discountType: "student", want: 50, wantLabel: "Student", wantPercentage: 50},
+		{name: "capped", discountType: "senior", want: 85, wantLabel: "Senior", wantPercentage: 15},
+		{name: "fixed amount", discountType: "disabled", want: 90, wantLabel: "Disabled", wantPercentage: 10},
+		{name: "stacked on the pre-discount fare", discountType: "student", discountTypes: []string{"senior", "disabled"}, want: 25, wantLabel: "Student + Senior + Disabled", wantPercentage: 75},
+		{name: "repeated discount counted once", discountType: "student", discountTypes: []string{"student"}, want: 50, wantLabel: "Student", wantPercentage: 50},
+		{name: "bus type not eligible", discountType: "ac-promo", want: 100, wantLabel: "None", wantIneligible: []string{"ac-promo"}},
+		{name: "bus type eligible", busType: "AC", discountType: "ac-promo", want: 150, wantLabel: "AC promo", wantPercentage: 25},
+		{name: "eligible ones still stack", discountType: "student", discountTypes: []string{"ac-promo"}, want: 50, wantLabel: "Student", wantPercentage: 50, wantIneligible: []string{"ac-promo"}},
+		{name: "minimum fare after discount", discountType: "staff", want: 10, wantLabel: "Staff", wantPercentage: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.FareRequest{
+				Distance:      10,
+				BusType:       tt.busType,
+				DiscountType:  tt.discountType,
+				DiscountTypes: tt.discountTypes,
+				DepartureTime: testDeparture,
+			}
+			response, err := service.CalculateFare(request, 10)
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.Fare != tt.want || response.DiscountApplied != tt.wantLabel || response.DiscountPercentage != tt.wantPercentage {
+				t.Errorf("got %v with %q (%v%%), want %v with %q (%v%%)",
+					response.Fare, response.DiscountApplied, response.DiscountPercentage, tt.want, tt.wantLabel, tt.wantPercentage)
+			}
+			if !slices.Equal(response.IneligibleDiscounts, tt.wantIneligible) {
+				t.Errorf("ineligible = %v, want %v", response.IneligibleDiscounts, tt.wantIneligible)
+			}
+			checkItemsAddUp(t, response)
+		})
+	}
+}
+
+func TestDiscountCombinations(t *testing.T) {
+	service := newTestFareService(t)
+	if err := service.SetDiscounts(testDiscountCatalog()); err != nil {
+		t.Fatalf("SetDiscounts: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		discountType  string
+		discountTypes []string
+		wantErr       string
+	}{
+		{"not stackable", "staff", []string{"student"}, `discount "staff" cannot be combined`},
+		{"not stackable second", "student", []string{"staff"}, `discount "staff" cannot be combined`},
+		{"unknown", "pensioner", nil, `unknown discount type "pensioner"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.FareRequest{Distance: 10, DiscountType: tt.discountType, DiscountTypes: tt.discountTypes, DepartureTime: testDeparture}
+			_, err := service.CalculateFare(request, 10)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetDiscountsRejectsInvalidCatalogs(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount models.Discount
+	}{
+		{"duplicate id", models.Discount{ID: "student", NameEn: "Student again", Percentage: 10}},
+		{"reserved id", models.Discount{ID: "none", NameEn: "None", Percentage: 10}},
+		{"missing name", models.Discount{ID: "child", Percentage: 10}},
+		{"percentage over 100", models.Discount{ID: "child", NameEn: "Child", Percentage: 120}},
+		{"no amount", models.Discount{ID: "child", NameEn: "Child"}},
+		{"unknown bus type", models.Discount{ID: "child", NameEn: "Child", Percentage: 10, BusTypes: []string{"sleeper"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestFareService(t)
+			catalog := testDiscountCatalog()
+			catalog.Discounts = append(catalog.Discounts, tt.discount)
+			if err := service.SetDiscounts(catalog); err == nil {
+				t.Error("SetDiscounts accepted the discount")
+			}
+		})
+	}
+}
@@ -1,108 +1,91 @@
 package services
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strings"
+	"log"
 
 	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/osrm"
+	"github.com/spectrum/bus-tk-backend/utils"
 )
 
-func getCoordinates(area string) (float64, float64, error) {
-	url := fmt.Sprintf("http://localhost:8111/search?q=%s&format=json", strings.ReplaceAll(area, " ", "%20"))
+// DistanceService calculates road distances between locations using OSRM,
+// falling back to a great-circle estimate when OSRM is unreachable
+type DistanceService struct {
+	osrmClient *osrm.Client
+	roadFactor float64 // Ratio of road distance to great-circle distance
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, 0, err
+// NewDistanceService creates a new distance service
+func NewDistanceService(osrmClient *osrm.Client, roadFactor float64) *DistanceService {
+	return &DistanceService{
+		osrmClient: osrmClient,
+		roadFactor: roadFactor,
 	}
-	defer resp.Body.Close()
-	body, _ := ioutil.ReadAll(resp.Body)
-	fmt.Println(string(body))
-	// var result map[string]interface{}
-	// json.Unmarshal(body, &result)
-
-	// features := result["features"].([]interface{})
-	// coords := features[0].(map[string]interface{})["geometry"].(map[string]interface{})["coordinates"].([]interface{})
-
-	// lon := coords[0].(float64)
-	// lat := coords[1].(float64)
-	return 0, 0, nil
 }
 
-func GetDistance(start, end models.Location) (float64, error) {
-	url := fmt.Sprintf("http://localhost:5111/route/v1/driving/%f,%f;%f,%f?overview=false", start.Lon, start.Lat, end.Lon, end.Lat)
+// ResolveDistance returns the trip distance in km for a fare request and where it came from.
+// A user-entered distance wins; otherwise OSRM is asked and, if it cannot be reached, the distance
+// is estimated. When OSRM answers that the locations cannot be routed (e.g. osrm.ErrNoRoute or
+// osrm.ErrNoSegment) the error is returned instead, as an estimate would price an impossible trip.
+// A zero distance with an empty source means the request has neither distance nor locations.
+func (s *DistanceService) ResolveDistance(ctx context.Context, request models.FareRequest) (float64, models.DistanceSource, error) {
+	if request.Distance > 0 {
+		return request.Distance, models.DistanceSourceUser, nil
+	}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		// Fallback to simple distance calculation if OSRM is not available
-		fmt.Printf("OSRM not available, using fallback distance calculation: %v\n", err)
-		// return calculateSimpleDistance(start, end), nil
-		return 0, err
+	if !hasCoordinates(request.StartLocation) || !hasCoordinates(request.EndLocation) {
+		return 0, "", nil
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	distance, err := s.GetDistance(ctx, request.StartLocation, request.EndLocation)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %v", err)
+		var osrmErr *osrm.Error
+		if errors.As(err, &osrmErr) || errors.Is(err, osrm.ErrNoRoute) {
+			return 0, "", fmt.Errorf("no road route between the locations: %w", err)
+		}
+		log.Printf("OSRM distance unavailable, estimating instead: %v", err)
+		return s.EstimateDistance(request.StartLocation, request.EndLocation), models.DistanceSourceEstimated, nil
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, fmt.Errorf("failed to parse JSON response: %v", err)
-	}
+	return distance, models.DistanceSourceOSRM, nil
+}
 
-	// Debug: Print the response for troubleshooting
-	fmt.Printf("OSRM Response: %+v\n", result)
+// EstimateDistance estimates the road distance in km from the great-circle distance
+func (s *DistanceService) EstimateDistance(start, end models.Location) float64 {
+	return utils.HaversineMeters(start.Lat, start.Lon, end.Lat, end.Lon) * s.roadFactor / 1000
+}
 
-	// Check if result is nil
-	if result == nil {
-		return 0, fmt.Errorf("received nil response from OSRM")
-	}
+// hasCoordinates reports whether a location carries coordinates
+func hasCoordinates(location models.Location) bool {
+	return location.Lat != 0 || location.Lon != 0
+}
 
-	// Check if routes key exists
-	routesInterface, exists := result["routes"]
-	if !exists {
-		return 0, fmt.Errorf("no routes found in OSRM response")
+// GetDistance returns the driving distance in km between two locations
+func (s *DistanceService) GetDistance(ctx context.Context, start, end models.Location) (float64, error) {
+	response, err := s.osrmClient.Route(ctx, []osrm.Coordinate{
+		{Lon: start.Lon, Lat: start.Lat},
+		{Lon: end.Lon, Lat: end.Lat},
+	}, nil)
+	if err != nil {
+		return 0, err
 	}
 
-	routes, ok := routesInterface.([]interface{})
-	if !ok {
-		return 0, fmt.Errorf("routes is not an array in OSRM response")
-	}
-	fmt.Println("Routes:", routes)
-	// Check if routes array is empty
-	if len(routes) == 0 {
-		return 0, fmt.Errorf("no routes available in OSRM response")
+	if len(response.Routes) == 0 {
+		return 0, osrm.ErrNoRoute
 	}
 
-	// Get first route
-	firstRoute, ok := routes[0].(map[string]interface{})
-	if !ok {
-		return 0, fmt.Errorf("first route is not a valid object")
-	}
-	fmt.Println("First Route:", firstRoute)
-	// Check if distance exists
-	distanceInterface, exists := firstRoute["distance"]
-	if !exists {
-		return 0, fmt.Errorf("no distance found in route summary")
-	}
-	distance, ok := distanceInterface.(float64)
-	if !ok {
-		return 0, fmt.Errorf("distance is not a valid number")
-	}
-	fmt.Println("Distance:", distance)
-	return distance / 1000, nil // convert to km
+	return response.Routes[0].Distance / 1000, nil // convert to km
 }
 
 // CheckOSRMHealth checks if the OSRM service is running
-func CheckOSRMHealth() bool {
-	resp, err := http.Get("http://localhost:5111/route/v1/driving/90.3563,23.8103;90.3563,23.8103?overview=false")
+func (s *DistanceService) CheckOSRMHealth(ctx context.Context) bool {
+	_, err := s.osrmClient.Nearest(ctx, osrm.Coordinate{Lon: 90.3563, Lat: 23.8103}, 1)
 	if err != nil {
-		fmt.Printf("OSRM health check failed: %v\n", err)
+		log.Printf("OSRM health check failed: %v", err)
 		return false
 	}
-	defer resp.Body.Close()
-	return resp.StatusCode == 200
+	return true
 }
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/osrm"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// testTrip goes from Gabtoli to Motijheel
+var testTrip = models.FareRequest{
+	StartLocation: models.Location{NameEn: "Gabtoli", Lat: 23.7838, Lon: 90.3440},
+	EndLocation:   models.Location{NameEn: "Motijheel", Lat: 23.7330, Lon: 90.4170},
+}
+
+// newOSRMServer serves one fixed OSRM reply and counts the requests it gets
+func newOSRMServer(t *testing.T, status int, body string) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if want := "/route/v1/driving/90.344000,23.783800;90.417000,23.733000"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestResolveDistance(t *testing.T) {
+	estimate := utils.HaversineMeters(23.7838, 90.3440, 23.7330, 90.4170) * 1.35 / 1000
+
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		want       float64
+		wantSource models.DistanceSource
+		wantErr    error
+	}{
+		{name: "road distance", status: http.StatusOK, body: `{"code": "Ok", "routes": [{"distance": 10250.5, "duration": 1500}]}`, want: 10.2505, wantSource: models.DistanceSourceOSRM},
+		{name: "no route", status: http.StatusBadRequest, body: `{"code": "NoRoute", "message": "Impossible route"}`, wantErr: osrm.ErrNoRoute},
+		{name: "off the road network", status: http.StatusBadRequest, body: `{"code": "NoSegment", "message": "Could not find a matching segment"}`, wantErr: osrm.ErrNoSegment},
+		{name: "ok without routes", status: http.StatusOK, body: `{"code": "Ok", "routes": []}`, wantErr: osrm.ErrNoRoute},
+		{name: "proxy error page", status: http.StatusBadGateway, body: `<html>Bad Gateway</html>`, want: estimate, wantSource: models.DistanceSourceEstimated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newOSRMServer(t, tt.status, tt.body)
+			service := NewDistanceService(osrm.NewClient(server.URL), 1.35)
+
+			distance, source, err := service.ResolveDistance(context.Background(), testTrip)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDistance: %v", err)
+			}
+			if math.Abs(distance-tt.want) > 1e-9 || source != tt.wantSource {
+				t.Errorf("got %v km from %s, want %v km from %s", distance, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestResolveDistanceWithoutOSRM(t *testing.T) {
+	server, requests := newOSRMServer(t, http.StatusOK, `{"code": "Ok", "routes": [{"distance": 10000}]}`)
+	service := NewDistanceService(osrm.NewClient(server.URL), 1.35)
+
+	// A distance entered by the user is used as is
+	request := testTrip
+	request.Distance = 7.5
+	distance, source, err := service.ResolveDistance(context.Background(), request)
+	if err != nil || distance != 7.5 || source != models.DistanceSourceUser {
+		t.Errorf("got %v km from %q, %v; want 7.5 km from the user", distance, source, err)
+	}
+
+	// Without locations there is nothing to resolve
+	distance, source, err = service.ResolveDistance(context.Background(), models.FareRequest{BusType: "nonAC"})
+	if err != nil || distance != 0 || source != "" {
+		t.Errorf("got %v km from %q, %v; want nothing", distance, source, err)
+	}
+	if *requests != 0 {
+		t.Errorf("OSRM was called %d times", *requests)
+	}
+
+	// An unreachable server falls back to the estimate
+	server.Close()
+	distance, source, err = service.ResolveDistance(context.Background(), testTrip)
+	if err != nil || source != models.DistanceSourceEstimated || distance != service.EstimateDistance(testTrip.StartLocation, testTrip.EndLocation) {
+		t.Errorf("got %v km from %q, %v; want the estimate", distance, source, err)
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/gtfs"
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// ExportService publishes the route network and its fares in open formats
+type ExportService struct {
+	routeService *RouteService
+	fareService  *FareService
+	options      gtfs.ExportOptions
+}
+
+// NewExportService creates a new export service
+func NewExportService(routeService *RouteService, fareService *FareService, options gtfs.ExportOptions) *ExportService {
+	return &ExportService{
+		routeService: routeService,
+		fareService:  fareService,
+		options:      options,
+	}
+}
+
+// GTFSFeed builds a GTFS feed of every route, priced for each boarding and
+// alighting stop pair in both directions
+func (s *ExportService) GTFSFeed() (*gtfs.Feed, error) {
+	network := s.routeService.Network()
+	start := time.Now().In(dhakaTimezone)
+	chart, err := s.fareChart(network, start)
+	if err != nil {
+		return nil, err
+	}
+
+	options := s.options
+	options.StartDate = start
+	return gtfs.FromNetwork(network, chart, options), nil
+}
+
+// fareChart quotes the undiscounted fare between every pair of stops on each route with
+// the fare table in effect on the given date. Time-of-day and holiday adjustments are
+// left out, as GTFS fares cannot vary by departure time.
+func (s *ExportService) fareChart(network models.RouteNetwork, on time.Time) (*models.FareChart, error) {
+	stops := make(map[string]models.Stop, len(network.Stops))
+	for _, stop := range network.Stops {
+		stops[stop.ID] = stop
+	}
+
+	chart := &models.FareChart{Routes: []models.FareChartRoute{}}
+	for _, route := range network.Routes {
+		cumulativeKm := make([]float64, len(route.StopIDs))
+		for i := 1; i < len(route.StopIDs); i++ {
+			previous, current := stops[route.StopIDs[i-1]], stops[route.StopIDs[i]]
+			cumulativeKm[i] = cumulativeKm[i-1] + utils.HaversineMeters(previous.Lat, previous.Lon, current.Lat, current.Lon)*s.options.RoadFactor/1000
+		}
+
+		entries := []models.FareChartEntry{}
+		seen := make(map[string]bool)
+		for from := range route.StopIDs {
+			for to := range route.StopIDs {
+				pair := route.StopIDs[from] + "|" + route.StopIDs[to]
+				distance := cumulativeKm[max(from, to)] - cumulativeKm[min(from, to)]
+				if from == to || seen[pair] || distance <= 0 {
+					continue
+				}
+				seen[pair] = true
+
+				fare, err := s.fareService.CalculateStaticFare(models.FareRequest{
+					Distance:      distance,
+					BusType:       route.BusType,
+					OperatorID:    route.OperatorID,
+					RouteID:       route.ID,
+					BoardingStop:  route.StopIDs[from],
+					AlightingStop: route.StopIDs[to],
+				}, distance, on)
+				if err != nil {
+					return nil, fmt.Errorf("failed to price route %q: %v", route.ID, err)
+				}
+				entries = append(entries, models.FareChartEntry{From: route.StopIDs[from], To: route.StopIDs[to], Fare: fare.Fare})
+			}
+		}
+		chart.Routes = append(chart.Routes, models.FareChartRoute{RouteID: route.ID, Fares: entries})
+	}
+
+	return chart, nil
+}
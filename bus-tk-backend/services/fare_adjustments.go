@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// ErrHolidaysNotSaved is returned when an added holiday could not be saved to the holiday store
+var ErrHolidaysNotSaved = errors.New("holiday calendar could not be saved")
+
+// SetHolidays indexes the holiday calendar used by fare adjustments
+func (s *FareService) SetHolidays(calendar *models.HolidayCalendar) error {
+	holidays := make(map[string][]models.Holiday)
+	for _, holiday := range calendar.Holidays {
+		if err := validateHoliday(holiday); err != nil {
+			return err
+		}
+		holidays[holiday.Date] = append(holidays[holiday.Date], holiday)
+	}
+
+	s.holidaysMu.Lock()
+	defer s.holidaysMu.Unlock()
+	s.holidays = holidays
+	s.calendar = slices.Clone(calendar.Holidays)
+	return nil
+}
+
+// SetHolidayStore sets the store the holiday calendar is saved to when holidays are added
+func (s *FareService) SetHolidayStore(store storage.HolidayStore) {
+	s.holidayStore = store
+}
+
+// AddHoliday adds a day to the holiday calendar, e.g. a hartal declared at short notice,
+// so holiday fare adjustments apply to it without a restart. The calendar is saved
+// first, and the holiday is only used once it has been saved.
+func (s *FareService) AddHoliday(holiday models.Holiday) (*models.Holiday, error) {
+	holiday.Date = strings.TrimSpace(holiday.Date)
+	holiday.Name = strings.TrimSpace(holiday.Name)
+	holiday.NameBn = strings.TrimSpace(holiday.NameBn)
+	holiday.Type = strings.ToLower(strings.TrimSpace(holiday.Type))
+	if err := validateHoliday(holiday); err != nil {
+		return nil, fmt.Errorf("invalid request: %v", err)
+	}
+
+	s.holidaysMu.Lock()
+	defer s.holidaysMu.Unlock()
+	for _, existing := range s.holidays[holiday.Date] {
+		if existing.Type == holiday.Type {
+			return nil, fmt.Errorf("invalid request: %s is already a %s holiday (%s)", holiday.Date, holiday.Type, existing.Name)
+		}
+	}
+
+	calendar := append(slices.Clone(s.calendar), holiday)
+	if s.holidayStore != nil {
+		if err := s.holidayStore.SaveHolidays(&models.HolidayCalendar{Holidays: calendar}); err != nil {
+			log.Printf("Error saving holiday calendar: %v", err)
+			return nil, ErrHolidaysNotSaved
+		}
+	}
+
+	if s.holidays == nil {
+		s.holidays = make(map[string][]models.Holiday)
+	}
+	s.holidays[holiday.Date] = append(s.holidays[holiday.Date], holiday)
+	s.calendar = calendar
+	return &holiday, nil
+}
+
+// holidaysOn returns the holidays on a date (YYYY-MM-DD)
+func (s *FareService) holidaysOn(date string) []models.Holiday {
+	s.holidaysMu.RLock()
+	defer s.holidaysMu.RUnlock()
+	return s.holidays[date]
+}
+
+// validateHoliday checks that a holiday has a valid date, a name and a type
+func validateHoliday(holiday models.Holiday) error {
+	if _, err := time.Parse("2006-01-02", holiday.Date); err != nil {
+		return fmt.Errorf("holiday %q: invalid date %q", holiday.Name, holiday.Date)
+	}
+	if holiday.Name == "" || holiday.Type == "" {
+		return fmt.Errorf("holiday on %s: name and type are required", holiday.Date)
+	}
+	return nil
+}
+
+// departureTime parses a request's departure time. Without one it returns the zero
+// time, so the current fare table applies without time-of-day or holiday adjustments.
+func departureTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	departure, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid request: departureTime must be an RFC 3339 time such as 2024-04-10T22:30:00+06:00")
+	}
+	return departure, nil
+}
+
+// adjustFare applies every matching adjustment of the table to a pre-discount fare
+func (s *FareService) adjustFare(table *fareTable, busType string, fare utils.Poisha, departure time.Time) (utils.Poisha, []models.AppliedAdjustment) {
+	var applied []models.AppliedAdjustment
+	departure = departure.In(dhakaTimezone)
+	for _, adjustment := range table.Adjustments {
+		holiday, ok := s.adjustmentApplies(adjustment, busType, departure)
+		if !ok {
+			continue
+		}
+
+		adjusted := fare
+		if adjustment.Multiplier != 0 {
+			adjusted = adjusted.Scale(adjustment.Multiplier)
+		}
+		adjusted = max(0, adjusted+utils.TakaToPoisha(adjustment.Surcharge))
+		applied = append(applied, models.AppliedAdjustment{
+			Name:      adjustment.Name,
+			Holiday:   holiday,
+			Amount:    (adjusted - fare).Taka(),
+			Regulated: adjustment.Regulated,
+		})
+		fare = adjusted
+	}
+	return fare, applied
+}
+
+// adjustmentApplies checks an adjustment's conditions against a departure in Dhaka
+// time, returning the name of the holiday that matched, if any
+func (s *FareService) adjustmentApplies(adjustment models.FareAdjustment, busType string, departure time.Time) (string, bool) {
+	if len(adjustment.BusTypes) > 0 && !slices.Contains(adjustment.BusTypes, busType) {
+		return "", false
+	}
+	if len(adjustment.Days) > 0 && !slices.Contains(adjustment.Days, strings.ToLower(departure.Weekday().String())) {
+		return "", false
+	}
+	if adjustment.StartTime != "" {
+		start, _ := parseClock(adjustment.StartTime)
+		end, _ := parseClock(adjustment.EndTime)
+		minute := departure.Hour()*60 + departure.Minute()
+		inWindow := minute >= start && minute < end
+		if start > end {
+			inWindow = minute >= start || minute < end
+		}
+		if !inWindow {
+			return "", false
+		}
+	}
+	if len(adjustment.HolidayTypes) > 0 {
+		for _, holiday := range s.holidaysOn(departure.Format("2006-01-02")) {
+			if slices.Contains(adjustment.HolidayTypes, holiday.Type) {
+				return holiday.Name, true
+			}
+		}
+		return "", false
+	}
+	return "", true
+}
+
+// validateAdjustment checks that an adjustment's conditions can be evaluated
+func validateAdjustment(table models.FareTable, adjustment models.FareAdjustment) error {
+	if adjustment.Name == "" {
+		return fmt.Errorf("adjustment without name")
+	}
+	if (adjustment.StartTime == "") != (adjustment.EndTime == "") {
+		return fmt.Errorf("adjustment %q: startTime and endTime must be set together", adjustment.Name)
+	}
+	if adjustment.StartTime != "" {
+		for _, clock := range []string{adjustment.StartTime, adjustment.EndTime} {
+			if _, err := parseClock(clock); err != nil {
+				return fmt.Errorf("adjustment %q: %v", adjustment.Name, err)
+			}
+		}
+	}
+	for _, day := range adjustment.Days {
+		if !slices.Contains(weekdayNames, day) {
+			return fmt.Errorf("adjustment %q: unknown day %q", adjustment.Name, day)
+		}
+	}
+	for _, busType := range adjustment.BusTypes {
+		if _, ok := table.RatesPerKm[busType]; !ok {
+			return fmt.Errorf("adjustment %q: unknown bus type %q", adjustment.Name, busType)
+		}
+	}
+	if adjustment.Multiplier < 0 {
+		return fmt.Errorf("adjustment %q: multiplier must not be negative", adjustment.Name)
+	}
+	return nil
+}
+
+// weekdayNames lists the day names accepted by adjustments
+var weekdayNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// parseClock converts HH:MM into minutes after midnight
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// applyOperatorOverride reprices a regulated fare for the request's operator.
+// Own per-km rates replace the regulated formula when no chart fare applies,
+// then the multiplier is applied.
+func (s *FareService) applyOperatorOverride(table *fareTable, request models.FareRequest, distance float64, fare utils.Poisha, method models.FareMethod) (utils.Poisha, []models.AppliedAdjustment) {
+	override := operatorOverride(table, request.OperatorID)
+	if override == nil {
+		return fare, nil
+	}
+
+	adjusted := fare
+	if rate, ok := override.RatesPerKm[request.BusType]; ok && method == models.FareMethodPerKm {
+		adjusted = max(utils.TakaToPoisha(rate).Scale(distance), utils.TakaToPoisha(override.MinimumFare))
+	}
+	if override.Multiplier > 0 {
+		adjusted = adjusted.Scale(override.Multiplier)
+	}
+	if adjusted == fare {
+		return fare, nil
+	}
+
+	return adjusted, []models.AppliedAdjustment{{Name: override.Name, Amount: (adjusted - fare).Taka()}}
+}
+
+// operatorOverride returns the table's override for an operator, if any
+func operatorOverride(table *fareTable, operatorID string) *models.OperatorOverride {
+	if operatorID == "" {
+		return nil
+	}
+	for i, override := range table.OperatorOverrides {
+		if override.OperatorID == operatorID {
+			return &table.OperatorOverrides[i]
+		}
+	}
+	return nil
+}
+
+// validateOperatorOverride checks an operator override's rates and multiplier
+func validateOperatorOverride(table models.FareTable, override models.OperatorOverride) error {
+	if override.OperatorID == "" || override.Name == "" {
+		return fmt.Errorf("operator override: operatorId and name are required")
+	}
+	for busType, rate := range override.RatesPerKm {
+		if _, ok := table.RatesPerKm[busType]; !ok || rate <= 0 {
+			return fmt.Errorf("operator override %q: invalid rate for bus type %q", override.OperatorID, busType)
+		}
+	}
+	if override.MinimumFare < 0 || override.Multiplier < 0 {
+		return fmt.Errorf("operator override %q: minimum fare and multiplier must not be negative", override.OperatorID)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+)
+
+// testAdjustedFareService returns a fare service with a late-night window, a Friday
+// surcharge and Eid (AC only) and hartal adjustments, with Eid on 27 May 2026
+func testAdjustedFareService(t *testing.T) *FareService {
+	t.Helper()
+	table := testFareTable()
+	table.Adjustments = []models.FareAdjustment{
+		{Name: "Late night", StartTime: "23:00", EndTime: "05:00", Multiplier: 1.2},
+		{Name: "Friday", Days: []string{"friday"}, Surcharge: 5},
+		{Name: "Eid service", HolidayTypes: []string{"eid"}, BusTypes: []string{"AC"}, Multiplier: 1.25},
+		{Name: "Hartal", HolidayTypes: []string{"hartal"}, Surcharge: 10},
+	}
+	service := newTestFareService(t, table)
+	if err := service.SetHolidays(&models.HolidayCalendar{Holidays: []models.Holiday{
+		{Date: "2026-05-27", Name: "Eid-ul-Adha", Type: "eid"},
+		{Date: "2026-06-17", Name: "Hartal", Type: "hartal"},
+	}}); err != nil {
+		t.Fatalf("SetHolidays: %v", err)
+	}
+	return service
+}
+
+func TestFareAdjustments(t *testing.T) {
+	service := testAdjustedFareService(t)
+
+	tests := []struct {
+		name            string
+		departure       string
+		busType         string
+		want            float64
+		wantAdjustments []string
+		wantHoliday     string
+	}{
+		{name: "no departure time", busType: "nonAC", want: 50},
+		{name: "weekday midday", departure: "2026-06-10T12:00:00+06:00", busType: "nonAC", want: 50},
+		{name: "late night", departure: "2026-06-10T23:30:00+06:00", busType: "nonAC", want: 60, wantAdjustments: []string{"Late night"}},
+		{name: "late night after midnight", departure: "2026-06-11T04:59:00+06:00", busType: "nonAC", want: 60, wantAdjustments: []string{"Late night"}},
+		{name: "window end is exclusive", departure: "2026-06-11T05:00:00+06:00", busType: "nonAC", want: 50},
+		{name: "window in Dhaka time", departure: "2026-06-10T17:30:00Z", busType: "nonAC", want: 60, wantAdjustments: []string{"Late night"}},
+		{name: "friday", departure: "2026-06-12T12:00:00+06:00", busType: "nonAC", want: 55, wantAdjustments: []string{"Friday"}},
+		{name: "friday in Dhaka, thursday in UTC", departure: "2026-06-11T23:30:00Z", busType: "nonAC", want: 55, wantAdjustments: []string{"Friday"}},
+		{name: "eid on AC", departure: "2026-05-27T12:00:00+06:00", busType: "AC", want: 125, wantAdjustments: []string{"Eid service"}, wantHoliday: "Eid-ul-Adha"},
+		{name: "eid skips non-AC", departure: "2026-05-27T12:00:00+06:00", busType: "nonAC", want: 50},
+		{name: "multiplier before surcharge", departure: "2026-06-17T23:30:00+06:00", busType: "nonAC", want: 70, wantAdjustments: []string{"Late night", "Hartal"}, wantHoliday: "Hartal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.FareRequest{Distance: 5, BusType: tt.busType, DepartureTime: tt.departure}
+			response, err := service.CalculateFare(request, 5)
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.Fare != tt.want || response.BaseRate != tt.want {
+				t.Errorf("fare = %v (base %v), want %v", response.Fare, response.BaseRate, tt.want)
+			}
+
+			var names []string
+			var holiday string
+			for _, adjustment := range response.Adjustments {
+				names = append(names, adjustment.Name)
+				if adjustment.Holiday != "" {
+					holiday = adjustment.Holiday
+				}
+			}
+			if !slices.Equal(names, tt.wantAdjustments) || holiday != tt.wantHoliday {
+				t.Errorf("adjustments = %v on %q, want %v on %q", names, holiday, tt.wantAdjustments, tt.wantHoliday)
+			}
+			checkItemsAddUp(t, response)
+		})
+	}
+}
+
+func TestCalculateStaticFareSkipsAdjustments(t *testing.T) {
+	service := testAdjustedFareService(t)
+
+	on := time.Date(2026, 6, 17, 0, 0, 0, 0, dhakaTimezone)
+	response, err := service.CalculateStaticFare(models.FareRequest{Distance: 5, BusType: "nonAC", DiscountType: "student"}, 5, on)
+	if err != nil {
+		t.Fatalf("CalculateStaticFare: %v", err)
+	}
+	if response.Fare != 50 || len(response.Adjustments) > 0 {
+		t.Errorf("got %v with adjustments %+v, want the unadjusted 50", response.Fare, response.Adjustments)
+	}
+}
+
+func TestAddHoliday(t *testing.T) {
+	service := testAdjustedFareService(t)
+	store := storage.NewJSONHolidayStore(filepath.Join(t.TempDir(), "holidays.json"))
+	service.SetHolidayStore(store)
+
+	hartalDay := models.FareRequest{Distance: 5, DepartureTime: "2026-06-24T12:00:00+06:00"}
+	if response, err := service.CalculateFare(hartalDay, 5); err != nil || response.Fare != 50 {
+		t.Fatalf("before adding the hartal got %v, %v; want 50", response, err)
+	}
+
+	added, err := service.AddHoliday(models.Holiday{Date: " 2026-06-24", Name: "Hartal ", Type: " Hartal"})
+	if err != nil {
+		t.Fatalf("AddHoliday: %v", err)
+	}
+	if *added != (models.Holiday{Date: "2026-06-24", Name: "Hartal", Type: "hartal"}) {
+		t.Errorf("added %+v, want it normalized", *added)
+	}
+	if response, err := service.CalculateFare(hartalDay, 5); err != nil || response.Fare != 60 {
+		t.Errorf("after adding the hartal got %v, %v; want 60", response, err)
+	}
+
+	// The saved calendar holds the loaded holidays and the new one
+	saved, err := store.LoadHolidays()
+	if err != nil {
+		t.Fatalf("LoadHolidays: %v", err)
+	}
+	if len(saved.Holidays) != 3 || saved.Holidays[2] != *added {
+		t.Errorf("saved calendar = %+v", saved.Holidays)
+	}
+
+	tests := []struct {
+		name    string
+		holiday models.Holiday
+	}{
+		{"same date and type", models.Holiday{Date: "2026-06-24", Name: "Second hartal", Type: "hartal"}},
+		{"bad date", models.Holiday{Date: "24/06/2026", Name: "Hartal", Type: "hartal"}},
+		{"missing type", models.Holiday{Date: "2026-06-25", Name: "Hartal"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.AddHoliday(tt.holiday); err == nil {
+				t.Error("AddHoliday accepted the holiday")
+			}
+		})
+	}
+
+	// Another type on the same date is a separate holiday
+	if _, err := service.AddHoliday(models.Holiday{Date: "2026-06-24", Name: "Bank holiday", Type: "public"}); err != nil {
+		t.Errorf("AddHoliday with another type: %v", err)
+	}
+}
+
+func TestAddHolidayNotSaved(t *testing.T) {
+	service := testAdjustedFareService(t)
+	service.SetHolidayStore(storage.NewJSONHolidayStore(filepath.Join(t.TempDir(), "missing", "holidays.json")))
+
+	_, err := service.AddHoliday(models.Holiday{Date: "2026-06-24", Name: "Hartal", Type: "hartal"})
+	if !errors.Is(err, ErrHolidaysNotSaved) {
+		t.Fatalf("err = %v, want ErrHolidaysNotSaved", err)
+	}
+
+	// A holiday that was not saved is not used either
+	response, err := service.CalculateFare(models.FareRequest{Distance: 5, DepartureTime: "2026-06-24T12:00:00+06:00"}, 5)
+	if err != nil || response.Fare != 50 {
+		t.Errorf("got %v, %v; want the unadjusted 50", response, err)
+	}
+}
+
+// testOverrideFareService returns a fare service where "seating" charges its own
+// non-AC rate, "premium" adds 10% and "express" does both, with the test fare chart
+func testOverrideFareService(t *testing.T) *FareService {
+	t.Helper()
+	table := testFareTable()
+	table.OperatorOverrides = []models.OperatorOverride{
+		{OperatorID: "seating", Name: "Seating service", RatesPerKm: map[string]float64{"nonAC": 15}, MinimumFare: 30},
+		{OperatorID: "premium", Name: "Premium service", Multiplier: 1.1},
+		{OperatorID: "express", Name: "Express service", RatesPerKm: map[string]float64{"nonAC": 12}, Multiplier: 1.5},
+	}
+	service := newTestFareService(t, table)
+	if err := service.SetFareChart(testFareChart()); err != nil {
+		t.Fatalf("SetFareChart: %v", err)
+	}
+	return service
+}
+
+func TestOperatorOverrides(t *testing.T) {
+	service := testOverrideFareService(t)
+
+	perKm := func(operatorID, busType string, distance float64) models.FareRequest {
+		return models.FareRequest{OperatorID: operatorID, BusType: busType, Distance: distance}
+	}
+	chart := func(operatorID string) models.FareRequest {
+		return models.FareRequest{OperatorID: operatorID, RouteID: "route-1", BoardingStop: "a", AlightingStop: "b"}
+	}
+
+	tests := []struct {
+		name           string
+		request        models.FareRequest
+		want           float64
+		wantAdjustment float64
+	}{
+		{name: "no operator", request: perKm("", "nonAC", 5), want: 50},
+		{name: "operator without override", request: perKm("local", "nonAC", 5), want: 50},
+		{name: "own rate", request: perKm("seating", "nonAC", 5), want: 75, wantAdjustment: 25},
+		{name: "own minimum fare", request: perKm("seating", "nonAC", 1), want: 30, wantAdjustment: 10},
+		{name: "no own rate for the bus type", request: perKm("seating", "AC", 5), want: 100},
+		{name: "multiplier", request: perKm("premium", "nonAC", 5), want: 55, wantAdjustment: 5},
+		{name: "own rate then multiplier", request: perKm("express", "nonAC", 5), want: 90, wantAdjustment: 40},
+		{name: "chart fare ignores own rates", request: chart("seating"), want: 25},
+		{name: "multiplier on chart fare", request: chart("premium"), want: 27.5, wantAdjustment: 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.DepartureTime = testDeparture
+			response, err := service.CalculateFare(tt.request, tt.request.Distance)
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.Fare != tt.want {
+				t.Errorf("fare = %v, want %v", response.Fare, tt.want)
+			}
+
+			var adjustment float64
+			for _, applied := range response.Adjustments {
+				adjustment += applied.Amount
+			}
+			if adjustment != tt.wantAdjustment {
+				t.Errorf("adjustments = %+v, want %v in total", response.Adjustments, tt.wantAdjustment)
+			}
+			checkItemsAddUp(t, response)
+		})
+	}
+}
+
+func TestCompareOperatorFares(t *testing.T) {
+	service := testOverrideFareService(t)
+
+	routes := []models.RouteSummary{
+		{Route: models.Route{ID: "seating-1", OperatorID: "seating", BusType: "nonAC"}},
+		{Route: models.Route{ID: "premium-ac", OperatorID: "premium", BusType: "AC"}},
+		{Route: models.Route{ID: "local-1", OperatorID: "local", BusType: "nonAC"}},
+	}
+	request := models.FareRequest{BoardingStop: "x", AlightingStop: "y", Distance: 5, DepartureTime: testDeparture}
+
+	response, err := service.CompareOperatorFares(request, 5, routes)
+	if err != nil {
+		t.Fatalf("CompareOperatorFares: %v", err)
+	}
+
+	var got []string
+	for _, fare := range response.Fares {
+		got = append(got, fmt.Sprintf("%s=%v", fare.RouteID, fare.Fare.Fare))
+	}
+	want := []string{"local-1=50", "seating-1=75", "premium-ac=110"}
+	if !slices.Equal(got, want) || response.Total != len(want) {
+		t.Errorf("fares = %v (total %d), want %v cheapest first", got, response.Total, want)
+	}
+}
+
+func TestHasChartFares(t *testing.T) {
+	service := testOverrideFareService(t)
+	charted := models.RouteSummary{Route: models.Route{ID: "route-1", OperatorID: "seating", BusType: "nonAC"}}
+	uncharted := models.RouteSummary{Route: models.Route{ID: "route-2", OperatorID: "local", BusType: "nonAC"}}
+
+	tests := []struct {
+		name                string
+		boarding, alighting string
+		routes              []models.RouteSummary
+		want                bool
+	}{
+		{name: "every route charted", boarding: "a", alighting: "b", routes: []models.RouteSummary{charted}, want: true},
+		{name: "charted in reverse", boarding: "c", alighting: "a", routes: []models.RouteSummary{charted}, want: true},
+		{name: "one route not charted", boarding: "a", alighting: "b", routes: []models.RouteSummary{charted, uncharted}},
+		{name: "stop pair not charted", boarding: "a", alighting: "d", routes: []models.RouteSummary{charted}},
+		{name: "no routes", boarding: "a", alighting: "b", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.FareRequest{BoardingStop: tt.boarding, AlightingStop: tt.alighting}
+			if got := service.HasChartFares(request, tt.routes); got != tt.want {
+				t.Errorf("HasChartFares = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFareServiceRejectsInvalidOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		override models.OperatorOverride
+	}{
+		{"missing name", models.OperatorOverride{OperatorID: "seating", Multiplier: 1.1}},
+		{"unknown bus type", models.OperatorOverride{OperatorID: "seating", Name: "Seating", RatesPerKm: map[string]float64{"sleeper": 15}}},
+		{"zero rate", models.OperatorOverride{OperatorID: "seating", Name: "Seating", RatesPerKm: map[string]float64{"nonAC": 0}}},
+		{"negative multiplier", models.OperatorOverride{OperatorID: "seating", Name: "Seating", Multiplier: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := testFareTable()
+			table.OperatorOverrides = []models.OperatorOverride{tt.override}
+			if _, err := NewFareService([]models.FareTable{table}); err == nil {
+				t.Error("NewFareService accepted the override")
+			}
+		})
+	}
+
+	table := testFareTable()
+	table.OperatorOverrides = []models.OperatorOverride{
+		{OperatorID: "premium", Name: "Premium", Multiplier: 1.1},
+		{OperatorID: "premium", Name: "Premium again", Multiplier: 1.2},
+	}
+	if _, err := NewFareService([]models.FareTable{table}); err == nil {
+		t.Error("NewFareService accepted duplicate overrides")
+	}
+}
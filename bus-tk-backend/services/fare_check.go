@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// CheckFare compares the amount a passenger was charged with the legal fare for the trip.
+// Without a departure time the legal fare has no time-of-day or holiday adjustments. The
+// table's regulation is only cited when every adjustment in the legal fare is regulated.
+func (s *FareService) CheckFare(request models.FareCheckRequest, distance float64) (*models.FareCheckResponse, error) {
+	charged := utils.TakaToPoisha(request.AmountCharged)
+	if charged <= 0 {
+		return nil, fmt.Errorf("invalid request: amountCharged must be positive")
+	}
+
+	legalFare, err := s.CalculateFare(request.FareRequest, distance)
+	if err != nil {
+		return nil, err
+	}
+
+	overcharge := max(0, charged-utils.TakaToPoisha(legalFare.Fare))
+	response := &models.FareCheckResponse{
+		LegalFare:             *legalFare,
+		AmountCharged:         charged.Taka(),
+		Overcharged:           overcharge > 0,
+		OverchargeAmount:      overcharge.Taka(),
+		OverchargeFormatted:   utils.FormatTaka(overcharge),
+		OverchargeFormattedBn: utils.FormatTakaBn(overcharge),
+	}
+	if table := s.tableByVersion(legalFare.FareTableVersion); table != nil && onlyRegulatedAdjustments(legalFare.Adjustments) {
+		response.Regulation = table.Regulation
+	}
+
+	return response, nil
+}
+
+// tableByVersion returns the fare table with the given version, or nil if there is none
+func (s *FareService) tableByVersion(version string) *fareTable {
+	for i := range s.tables {
+		if s.tables[i].Version == version {
+			return &s.tables[i]
+		}
+	}
+	return nil
+}
+
+// onlyRegulatedAdjustments reports whether every applied adjustment is part of the regulation
+func onlyRegulatedAdjustments(adjustments []models.AppliedAdjustment) bool {
+	for _, adjustment := range adjustments {
+		if !adjustment.Regulated {
+			return false
+		}
+	}
+	return true
+}
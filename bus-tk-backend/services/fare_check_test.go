@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+func TestCheckFare(t *testing.T) {
+	older := testFareTable()
+	older.Version, older.EffectiveFrom, older.Regulation = "2022-01", "2022-01-01", "Older regulation"
+	older.RatesPerKm = map[string]float64{"nonAC": 5, "AC": 8}
+	service := newTestFareService(t, testFareTable(), older)
+
+	tests := []struct {
+		name           string
+		charged        float64
+		departure      string
+		wantOver       bool
+		wantAmount     float64
+		wantFormatted  string
+		wantRegulation string
+	}{
+		{name: "legal fare", charged: 50, departure: testDeparture, wantFormatted: "Tk 0", wantRegulation: "Test regulation"},
+		{name: "undercharged", charged: 40, departure: testDeparture, wantFormatted: "Tk 0", wantRegulation: "Test regulation"},
+		{name: "one poisha over", charged: 50.01, departure: testDeparture, wantOver: true, wantAmount: 0.01, wantFormatted: "Tk 0.01", wantRegulation: "Test regulation"},
+		{name: "overcharged", charged: 70, departure: testDeparture, wantOver: true, wantAmount: 20, wantFormatted: "Tk 20", wantRegulation: "Test regulation"},
+		{name: "checked against the table in effect", charged: 50, departure: "2023-06-01T12:00:00+06:00", wantOver: true, wantAmount: 25, wantFormatted: "Tk 25", wantRegulation: "Older regulation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.FareCheckRequest{
+				FareRequest:   models.FareRequest{Distance: 5, DepartureTime: tt.departure},
+				AmountCharged: tt.charged,
+			}
+			response, err := service.CheckFare(request, 5)
+			if err != nil {
+				t.Fatalf("CheckFare: %v", err)
+			}
+			if response.Overcharged != tt.wantOver || response.OverchargeAmount != tt.wantAmount || response.OverchargeFormatted != tt.wantFormatted {
+				t.Errorf("got overcharged %v by %v (%q), want %v by %v (%q)",
+					response.Overcharged, response.OverchargeAmount, response.OverchargeFormatted, tt.wantOver, tt.wantAmount, tt.wantFormatted)
+			}
+			if response.Regulation != tt.wantRegulation || response.LegalFare.FareTableVersion == "" {
+				t.Errorf("regulation = %q from table %q, want %q", response.Regulation, response.LegalFare.FareTableVersion, tt.wantRegulation)
+			}
+			if response.AmountCharged != tt.charged {
+				t.Errorf("amountCharged = %v, want %v", response.AmountCharged, tt.charged)
+			}
+		})
+	}
+}
+
+func TestCheckFareRejectsInvalidRequests(t *testing.T) {
+	service := newTestFareService(t)
+
+	tests := []struct {
+		name    string
+		request models.FareCheckRequest
+	}{
+		{"nothing charged", models.FareCheckRequest{FareRequest: models.FareRequest{Distance: 5}}},
+		{"negative amount", models.FareCheckRequest{FareRequest: models.FareRequest{Distance: 5}, AmountCharged: -10}},
+		{"less than a poisha", models.FareCheckRequest{FareRequest: models.FareRequest{Distance: 5}, AmountCharged: 0.004}},
+		{"trip cannot be priced", models.FareCheckRequest{FareRequest: models.FareRequest{Distance: 5, BusType: "sleeper"}, AmountCharged: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.DepartureTime = testDeparture
+			if _, err := service.CheckFare(tt.request, 5); err == nil {
+				t.Error("CheckFare accepted the request")
+			}
+		})
+	}
+}
+
+func TestCheckFareAdjustments(t *testing.T) {
+	table := testFareTable()
+	table.Adjustments = []models.FareAdjustment{
+		{Name: "Late night", StartTime: "23:00", EndTime: "05:00", Multiplier: 1.2},
+		{Name: "Eid fare", HolidayTypes: []string{"eid"}, Surcharge: 5, Regulated: true},
+	}
+	service := newTestFareService(t, table)
+	if err := service.SetHolidays(&models.HolidayCalendar{Holidays: []models.Holiday{
+		{Date: "2026-05-27", Name: "Eid-ul-Adha", Type: "eid"},
+	}}); err != nil {
+		t.Fatalf("SetHolidays: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		departure      string
+		charged        float64
+		wantLegal      float64
+		wantRegulation string
+	}{
+		{name: "no departure time", charged: 60, wantLegal: 50, wantRegulation: "Test regulation"},
+		{name: "unregulated adjustment", departure: "2026-06-10T23:30:00+06:00", charged: 60, wantLegal: 60},
+		{name: "regulated adjustment", departure: "2026-05-27T12:00:00+06:00", charged: 60, wantLegal: 55, wantRegulation: "Test regulation"},
+		{name: "regulated and unregulated", departure: "2026-05-27T23:30:00+06:00", charged: 60, wantLegal: 65},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.FareCheckRequest{
+				FareRequest:   models.FareRequest{Distance: 5, DepartureTime: tt.departure},
+				AmountCharged: tt.charged,
+			}
+			response, err := service.CheckFare(request, 5)
+			if err != nil {
+				t.Fatalf("CheckFare: %v", err)
+			}
+			if response.LegalFare.Fare != tt.wantLegal || response.Overcharged != (tt.charged > tt.wantLegal) {
+				t.Errorf("legal fare = %v (overcharged %v), want %v", response.LegalFare.Fare, response.Overcharged, tt.wantLegal)
+			}
+			if response.Regulation != tt.wantRegulation {
+				t.Errorf("regulation = %q, want %q", response.Regulation, tt.wantRegulation)
+			}
+		})
+	}
+}
@@ -1,17 +1,247 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+	"github.com/spectrum/bus-tk-backend/utils"
 )
 
+// maxFareLegs limits the number of legs priced in one multi-leg request
+const maxFareLegs = 8
+
+// dhakaTimezone is used to interpret fare table effective dates
+var dhakaTimezone = time.FixedZone("Asia/Dhaka", 6*60*60)
+
+// fareTable is a fare table with its parsed effective date
+type fareTable struct {
+	models.FareTable
+	effectiveFrom time.Time
+}
+
 // FareService handles fare calculation business logic
-type FareService struct{}
+type FareService struct {
+	tables     []fareTable                 // Sorted by effective date, oldest first
+	chartFares map[string]utils.Poisha     // Chart fares keyed by route and stop pair
+	holidays   map[string][]models.Holiday // Holiday calendar keyed by date (YYYY-MM-DD)
+	calendar   []models.Holiday            // Holiday calendar in file order
+	holidaysMu sync.RWMutex                // Guards holidays and calendar, which admins can extend at runtime
+
+	holidayStore storage.HolidayStore // Where added holidays are saved; nil keeps them in memory only
+
+	discounts     []models.Discount          // Discount catalog in file order
+	discountsByID map[string]models.Discount // Discount catalog keyed by ID
+}
+
+// NewFareService creates a new fare service from the given fare tables
+func NewFareService(tables []models.FareTable) (*FareService, error) {
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("at least one fare table is required")
+	}
+
+	service := &FareService{}
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		if err := validateFareTable(table); err != nil {
+			return nil, fmt.Errorf("fare table %q: %v", table.Version, err)
+		}
+		if seen[table.Version] {
+			return nil, fmt.Errorf("duplicate fare table version %q", table.Version)
+		}
+		seen[table.Version] = true
+
+		effectiveFrom, _ := time.ParseInLocation("2006-01-02", table.EffectiveFrom, dhakaTimezone)
+		service.tables = append(service.tables, fareTable{FareTable: table, effectiveFrom: effectiveFrom})
+	}
+
+	sort.Slice(service.tables, func(i, j int) bool {
+		return service.tables[i].effectiveFrom.Before(service.tables[j].effectiveFrom)
+	})
+
+	return service, nil
+}
+
+// LoadFareTables reads the fare tables from a JSON file
+func LoadFareTables(path string) ([]models.FareTable, error) {
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fare tables: %v", err)
+	}
+
+	var file models.FareTableFile
+	if err := json.Unmarshal(jsonData, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse fare tables: %v", err)
+	}
+
+	return file.Tables, nil
+}
 
-// NewFareService creates a new fare service
-func NewFareService() *FareService {
-	return &FareService{}
+// LoadFareChart reads a BRTA fare chart from a JSON file
+func LoadFareChart(path string) (*models.FareChart, error) {
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fare chart: %v", err)
+	}
+
+	var chart models.FareChart
+	if err := json.Unmarshal(jsonData, &chart); err != nil {
+		return nil, fmt.Errorf("failed to parse fare chart: %v", err)
+	}
+
+	return &chart, nil
+}
+
+// SetFareChart indexes the chart fares used for stop-to-stop quotes
+func (s *FareService) SetFareChart(chart *models.FareChart) error {
+	if err := ValidateFareChart(chart); err != nil {
+		return err
+	}
+
+	chartFares := make(map[string]utils.Poisha)
+	for _, route := range chart.Routes {
+		for _, entry := range route.Fares {
+			chartFares[fareChartKey(route.RouteID, entry.From, entry.To)] = utils.TakaToPoisha(entry.Fare)
+		}
+	}
+
+	s.chartFares = chartFares
+	return nil
+}
+
+// ValidateFareChart checks that every chart entry names a route and stop pair and has a
+// positive fare. The GTFS importer uses it to reject charts the server would refuse.
+func ValidateFareChart(chart *models.FareChart) error {
+	for _, route := range chart.Routes {
+		if route.RouteID == "" {
+			return fmt.Errorf("fare chart route without routeId")
+		}
+		for _, entry := range route.Fares {
+			if entry.From == "" || entry.To == "" || !(entry.Fare > 0) {
+				return fmt.Errorf("invalid fare chart entry on route %q: %s-%s", route.RouteID, entry.From, entry.To)
+			}
+		}
+	}
+	return nil
+}
+
+// fareChartKey builds the lookup key for a route and stop pair
+func fareChartKey(routeID, from, to string) string {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+	return normalize(routeID) + "|" + normalize(from) + "|" + normalize(to)
+}
+
+// chartFare looks up the chart fare for the request's route and stop pair.
+// Charts are published for one direction only, so the reverse pair is tried too.
+func (s *FareService) chartFare(request models.FareRequest) (utils.Poisha, bool) {
+	if request.RouteID == "" || request.BoardingStop == "" || request.AlightingStop == "" {
+		return 0, false
+	}
+	if fare, ok := s.chartFares[fareChartKey(request.RouteID, request.BoardingStop, request.AlightingStop)]; ok {
+		return fare, true
+	}
+	fare, ok := s.chartFares[fareChartKey(request.RouteID, request.AlightingStop, request.BoardingStop)]
+	return fare, ok
+}
+
+// validateFareTable checks that a fare table is complete and consistent
+func validateFareTable(table models.FareTable) error {
+	if table.Version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if _, err := time.Parse("2006-01-02", table.EffectiveFrom); err != nil {
+		return fmt.Errorf("invalid effectiveFrom date %q", table.EffectiveFrom)
+	}
+	for _, busType := range []models.BusType{models.BusTypeNonAC, models.BusTypeAC} {
+		if table.RatesPerKm[string(busType)] <= 0 {
+			return fmt.Errorf("missing rate per km for bus type %q", busType)
+		}
+	}
+	if table.MinimumFare < 0 || table.MinimumFareAfterDiscount < 0 {
+		return fmt.Errorf("minimum fares must not be negative")
+	}
+	if rounding := table.Rounding; rounding != nil {
+		switch models.RoundingMode(rounding.Mode) {
+		case models.RoundingModeNearest, models.RoundingModeCeil, models.RoundingModeFloor:
+		default:
+			return fmt.Errorf("unknown rounding mode %q", rounding.Mode)
+		}
+		if utils.TakaToPoisha(rounding.Step) <= 0 {
+			return fmt.Errorf("rounding step must be at least one poisha")
+		}
+	}
+	for _, rule := range table.TransferRules {
+		if rule.Name == "" {
+			return fmt.Errorf("transfer rule without name")
+		}
+		for _, busType := range []string{rule.FromBusType, rule.ToBusType} {
+			if _, ok := table.RatesPerKm[busType]; busType != "" && !ok {
+				return fmt.Errorf("transfer rule %q: unknown bus type %q", rule.Name, busType)
+			}
+		}
+		if rule.DiscountPercentage < 0 || rule.DiscountPercentage > 100 || rule.DiscountAmount < 0 {
+			return fmt.Errorf("transfer rule %q: discounts must be between 0 and the full fare", rule.Name)
+		}
+	}
+	for _, adjustment := range table.Adjustments {
+		if err := validateAdjustment(table, adjustment); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool)
+	for _, override := range table.OperatorOverrides {
+		if err := validateOperatorOverride(table, override); err != nil {
+			return err
+		}
+		if seen[override.OperatorID] {
+			return fmt.Errorf("duplicate operator override %q", override.OperatorID)
+		}
+		seen[override.OperatorID] = true
+	}
+	return nil
+}
+
+// activeTable returns the fare table in effect at the given time, or now for the zero time
+func (s *FareService) activeTable(at time.Time) (*fareTable, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	for i := len(s.tables) - 1; i >= 0; i-- {
+		if !s.tables[i].effectiveFrom.After(at) {
+			return &s.tables[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no fare table in effect on %s", at.In(dhakaTimezone).Format("2006-01-02"))
+}
+
+// pricedFare holds the steps of one fare calculation
+type pricedFare struct {
+	method           models.FareMethod
+	baseFare         utils.Poisha // Pre-discount fare, adjustments included
+	adjustments      []models.AppliedAdjustment
+	transferRule     string
+	transferDiscount utils.Poisha
+	discounts        discountQuote
+	fare             utils.Poisha // Final fare after rounding
+	items            []models.FareLineItem
+}
+
+// addItem records a fare step that changed the fare by amount
+func (p *pricedFare) addItem(itemType models.FareLineItemType, description string, amount utils.Poisha) {
+	p.items = append(p.items, models.FareLineItem{
+		Type:        string(itemType),
+		Description: description,
+		Amount:      amount.Taka(),
+	})
 }
 
 // CalculateFare calculates the bus fare based on the request
@@ -22,72 +252,359 @@ func (s *FareService) CalculateFare(request models.FareRequest, distance float64
 	}
 
 	// Set defaults
+	request = withFareDefaults(request)
+
+	discounts, err := s.resolveDiscounts(request.DiscountType, request.DiscountTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	departure, err := departureTime(request.DepartureTime)
+	if err != nil {
+		return nil, err
+	}
+	table, err := s.activeTable(departure)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate fare
+	priced, err := s.price(table, request, distance, departure, discounts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := priced.response(table, request, distance)
+	return &response, nil
+}
+
+// CalculateStaticFare quotes the regulated fare in effect on the given date without
+// time-of-day or holiday adjustments or discounts, for published fare charts that
+// must not depend on when they are generated
+func (s *FareService) CalculateStaticFare(request models.FareRequest, distance float64, on time.Time) (*models.FareResponse, error) {
+	if err := s.validateRequest(request); err != nil {
+		return nil, err
+	}
+	request = withFareDefaults(request)
+	request.DiscountType, request.DiscountTypes, request.DepartureTime = string(models.DiscountTypeNone), nil, ""
+
+	table, err := s.activeTable(on)
+	if err != nil {
+		return nil, err
+	}
+
+	// A zero departure skips the time-dependent adjustments
+	priced, err := s.price(table, request, distance, time.Time{}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := priced.response(table, request, distance)
+	return &response, nil
+}
+
+// CalculateMultiLegFare prices the legs of one journey with the fare table in effect
+// when it departs, reduces each leg after the first by the first matching transfer
+// rule and applies the journey's discount to every leg. distances holds the trip
+// distance of each leg.
+func (s *FareService) CalculateMultiLegFare(request models.MultiLegFareRequest, distances []float64) (*models.MultiLegFareResponse, error) {
+	if len(request.Legs) == 0 {
+		return nil, fmt.Errorf("invalid request: at least one leg is required")
+	}
+	if len(request.Legs) > maxFareLegs {
+		return nil, fmt.Errorf("invalid request: at most %d legs are allowed", maxFareLegs)
+	}
+	if len(distances) != len(request.Legs) {
+		return nil, fmt.Errorf("expected %d leg distances, got %d", len(request.Legs), len(distances))
+	}
+	if request.DiscountType == "" {
+		request.DiscountType = string(models.DiscountTypeNone)
+	}
+	discounts, err := s.resolveDiscounts(request.DiscountType, request.DiscountTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole journey is priced with the table in effect when it starts
+	if request.DepartureTime == "" {
+		request.DepartureTime = request.Legs[0].DepartureTime
+	}
+	journeyDeparture, err := departureTime(request.DepartureTime)
+	if err != nil {
+		return nil, err
+	}
+	table, err := s.activeTable(journeyDeparture)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.MultiLegFareResponse{
+		Legs:             make([]models.LegFare, 0, len(request.Legs)),
+		FareTableVersion: table.Version,
+	}
+	var totalFare, totalBaseFare, transferDiscount utils.Poisha
+	for i, leg := range request.Legs {
+		if err := s.validateRequest(leg); err != nil {
+			return nil, fmt.Errorf("leg %d: %v", i+1, err)
+		}
+		if (leg.DiscountType != "" && leg.DiscountType != request.DiscountType) || len(leg.DiscountTypes) > 0 {
+			return nil, fmt.Errorf("invalid request: leg %d has its own discount type, set discountType on the journey instead", i+1)
+		}
+		leg.DiscountType = request.DiscountType
+		leg = withFareDefaults(leg)
+
+		departure := journeyDeparture
+		if leg.DepartureTime != "" {
+			if departure, err = departureTime(leg.DepartureTime); err != nil {
+				return nil, fmt.Errorf("leg %d: %v", i+1, err)
+			}
+		}
+
+		var rule *models.TransferRule
+		if i > 0 {
+			rule = transferRule(table, response.Legs[i-1].BusType, leg.BusType)
+		}
+		priced, err := s.price(table, leg, distances[i], departure, discounts, rule)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %v", i+1, err)
+		}
+
+		response.Legs = append(response.Legs, models.LegFare{
+			FareResponse:     priced.response(table, leg, distances[i]),
+			TransferRule:     priced.transferRule,
+			TransferDiscount: priced.transferDiscount.Taka(),
+		})
+		totalFare += priced.fare
+		totalBaseFare += priced.baseFare
+		transferDiscount += priced.transferDiscount
+		if priced.discounts.label != "None" || response.DiscountApplied == "" {
+			response.DiscountApplied = priced.discounts.label
+		}
+	}
+
+	response.TotalFare = totalFare.Taka()
+	response.TotalFareFormatted = utils.FormatTaka(totalFare)
+	response.TotalFareFormattedBn = utils.FormatTakaBn(totalFare)
+	response.TotalBaseFare = totalBaseFare.Taka()
+	response.TransferDiscount = transferDiscount.Taka()
+
+	// Effective discount over the whole journey, after transfer rules
+	if payable := totalBaseFare - transferDiscount; payable > 0 {
+		response.DiscountPercentage = math.Round(float64(max(0, payable-totalFare))/float64(payable)*10000) / 100
+	}
+
+	return response, nil
+}
+
+// price runs the fare steps for one trip: the regulated base fare, operator,
+// time-of-day and holiday adjustments, an optional transfer rule, the passenger's
+// discounts and finally the table's rounding policy. A zero departure skips the
+// time-of-day and holiday adjustments.
+func (s *FareService) price(table *fareTable, request models.FareRequest, distance float64, departure time.Time, discounts []models.Discount, rule *models.TransferRule) (*pricedFare, error) {
+	fare, method, err := s.baseFare(table, request, distance)
+	if err != nil {
+		return nil, err
+	}
+
+	priced := &pricedFare{method: method}
+	if method == models.FareMethodChart {
+		priced.addItem(models.FareLineItemChartFare, fmt.Sprintf("Chart fare %s to %s", request.BoardingStop, request.AlightingStop), fare)
+	} else {
+		rate := table.RatesPerKm[request.BusType]
+		charge := distanceCharge(table, distance, request.BusType)
+		priced.addItem(models.FareLineItemDistanceCharge, fmt.Sprintf("%.2f km at %s/km", distance, utils.FormatTaka(utils.TakaToPoisha(rate))), charge)
+		if fare > charge {
+			priced.addItem(models.FareLineItemMinimumFare, "Minimum fare "+utils.FormatTaka(fare), fare-charge)
+		}
+	}
+
+	fare, priced.adjustments = s.applyOperatorOverride(table, request, distance, fare, method)
+	if !departure.IsZero() {
+		var timeAdjustments []models.AppliedAdjustment
+		fare, timeAdjustments = s.adjustFare(table, request.BusType, fare, departure)
+		priced.adjustments = append(priced.adjustments, timeAdjustments...)
+	}
+	for _, adjustment := range priced.adjustments {
+		priced.addItem(models.FareLineItemAdjustment, adjustment.Name, utils.TakaToPoisha(adjustment.Amount))
+	}
+	priced.baseFare = fare
+
+	// Transfer rules apply to the pre-discount fare, so discounts stay proportional on every leg
+	if rule != nil {
+		priced.transferRule = rule.Name
+		priced.transferDiscount = min(fare, fare.Percent(rule.DiscountPercentage)+utils.TakaToPoisha(rule.DiscountAmount))
+		priced.addItem(models.FareLineItemTransfer, rule.Name, -priced.transferDiscount)
+	}
+
+	priced.discounts = s.applyDiscounts(table, fare-priced.transferDiscount, request.BusType, discounts)
+	for _, discount := range priced.discounts.applied {
+		priced.addItem(models.FareLineItemDiscount, discount.Name, -utils.TakaToPoisha(discount.Amount))
+	}
+	if topUp := priced.discounts.topUp; topUp > 0 {
+		priced.addItem(models.FareLineItemMinimumFare, "Minimum fare after discount "+utils.FormatTaka(priced.discounts.fare), topUp)
+	}
+
+	priced.fare = roundFare(table, priced.discounts.fare)
+	if rounding := priced.fare - priced.discounts.fare; rounding != 0 {
+		policy := table.Rounding
+		priced.addItem(models.FareLineItemRounding, fmt.Sprintf("Rounded (%s) to %s", policy.Mode, utils.FormatTaka(utils.TakaToPoisha(policy.Step))), rounding)
+	}
+	return priced, nil
+}
+
+// response converts a priced fare into the API response
+func (p *pricedFare) response(table *fareTable, request models.FareRequest, distance float64) models.FareResponse {
+	return models.FareResponse{
+		Fare:                p.fare.Taka(),
+		FareFormatted:       utils.FormatTaka(p.fare),
+		FareFormattedBn:     utils.FormatTakaBn(p.fare),
+		Distance:            distance,
+		BusType:             request.BusType,
+		OperatorID:          request.OperatorID,
+		BaseRate:            p.baseFare.Taka(),
+		DiscountApplied:     p.discounts.label,
+		DiscountPercentage:  p.discounts.percentage,
+		Discounts:           p.discounts.applied,
+		IneligibleDiscounts: p.discounts.ineligible,
+		FareTableVersion:    table.Version,
+		FareMethod:          string(p.method),
+		Adjustments:         p.adjustments,
+		Breakdown:           p.breakdown(table, request),
+	}
+}
+
+// breakdown itemizes the priced fare
+func (p *pricedFare) breakdown(table *fareTable, request models.FareRequest) models.FareBreakdown {
+	breakdown := models.FareBreakdown{
+		Items: p.items,
+		Total: p.fare.Taka(),
+	}
+	if p.method == models.FareMethodPerKm {
+		breakdown.RatePerKm = table.RatesPerKm[request.BusType]
+	}
+	return breakdown
+}
+
+// withFareDefaults fills in the bus and discount types a request left empty
+func withFareDefaults(request models.FareRequest) models.FareRequest {
 	if request.BusType == "" {
 		request.BusType = string(models.BusTypeNonAC)
 	}
 	if request.DiscountType == "" {
 		request.DiscountType = string(models.DiscountTypeNone)
 	}
-	// Calculate fare
-	fare, baseRate, discountApplied, discountPercentage := s.calculateFare(distance, request.BusType, request.DiscountType)
+	return request
+}
 
-	response := &models.FareResponse{
-		Fare:               fare,
-		Distance:           distance,
-		BusType:            request.BusType,
-		BaseRate:           baseRate,
-		DiscountApplied:    discountApplied,
-		DiscountPercentage: discountPercentage,
+// baseFare returns the regulated pre-discount fare: the chart fare when one
+// exists, otherwise the per-km formula
+func (s *FareService) baseFare(table *fareTable, request models.FareRequest, distance float64) (utils.Poisha, models.FareMethod, error) {
+	if _, ok := table.RatesPerKm[request.BusType]; !ok {
+		return 0, "", fmt.Errorf("invalid request: unknown bus type %q", request.BusType)
 	}
 
-	return response, nil
+	if fare, ok := s.chartFare(request); ok {
+		return fare, models.FareMethodChart, nil
+	}
+	if distance <= 0 {
+		return 0, "", fmt.Errorf("unable to determine trip distance and no chart fare applies")
+	}
+	return s.perKmFare(table, distance, request.BusType), models.FareMethodPerKm, nil
 }
 
-// validateRequest validates the fare calculation request
-func (s *FareService) validateRequest(request models.FareRequest) error {
-	if request.Distance <= 0 && (request.StartLocation.NameEn == "" || request.EndLocation.NameEn == "") {
-		return fmt.Errorf("invalid request: must provide either distance or both start and end locations")
+// roundFare applies the table's rounding policy to a final fare
+func roundFare(table *fareTable, fare utils.Poisha) utils.Poisha {
+	if table.Rounding == nil {
+		return fare
+	}
+
+	step := utils.TakaToPoisha(table.Rounding.Step)
+	switch models.RoundingMode(table.Rounding.Mode) {
+	case models.RoundingModeCeil:
+		return fare.RoundUp(step)
+	case models.RoundingModeFloor:
+		return fare.RoundDown(step)
+	default:
+		return fare.RoundNearest(step)
 	}
-	return nil
 }
 
-// calculateFare calculates the bus fare based on distance, bus type, and discount
-func (s *FareService) calculateFare(distance float64, busType, discountType string) (float64, float64, string, float64) {
-	// Base rates per km
-	baseRate := 12.0 // Non-AC base rate
-	if busType == string(models.BusTypeAC) {
-		baseRate = 18.0 // AC bus base rate
+// CompareOperatorFares quotes a stop pair on each of the given routes, applying
+// every route's operator override and bus type, cheapest first
+func (s *FareService) CompareOperatorFares(request models.FareRequest, distance float64, routes []models.RouteSummary) (*models.FareComparisonResponse, error) {
+	response := &models.FareComparisonResponse{
+		BoardingStop:  request.BoardingStop,
+		AlightingStop: request.AlightingStop,
+		Fares:         []models.OperatorFare{},
 	}
 
-	// Calculate base fare
-	baseFare := distance * baseRate
+	for _, route := range routes {
+		routeRequest := request
+		routeRequest.RouteID = route.ID
+		routeRequest.OperatorID = route.OperatorID
+		routeRequest.BusType = route.BusType
 
-	// Apply minimum fare
-	if baseFare < 20.0 {
-		baseFare = 20.0
+		fare, err := s.CalculateFare(routeRequest, distance)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %v", route.ID, err)
+		}
+		response.Fares = append(response.Fares, models.OperatorFare{
+			Operator:  route.Operator,
+			RouteID:   route.ID,
+			RouteName: route.NameEn,
+			Fare:      *fare,
+		})
 	}
 
-	// Apply discounts
-	discountApplied := "None"
-	discountPercentage := 0.0
+	sort.SliceStable(response.Fares, func(i, j int) bool {
+		return response.Fares[i].Fare.Fare < response.Fares[j].Fare.Fare
+	})
+	response.Total = len(response.Fares)
+	return response, nil
+}
 
-	switch discountType {
-	case string(models.DiscountTypeStudent):
-		discountPercentage = 50.0
-		discountApplied = "Student Discount"
-	case string(models.DiscountTypePass):
-		discountPercentage = 20.0
-		discountApplied = "Monthly Pass"
+// HasChartFares reports whether every route has a chart fare between the request's
+// stops, so comparing them needs no trip distance
+func (s *FareService) HasChartFares(request models.FareRequest, routes []models.RouteSummary) bool {
+	for _, route := range routes {
+		routeRequest := request
+		routeRequest.RouteID = route.ID
+		if _, ok := s.chartFare(routeRequest); !ok {
+			return false
+		}
 	}
+	return true
+}
 
-	// Calculate final fare
-	discountAmount := baseFare * (discountPercentage / 100.0)
-	finalFare := baseFare - discountAmount
+// transferRule returns the first transfer rule matching a change between bus types
+func transferRule(table *fareTable, fromBusType, toBusType string) *models.TransferRule {
+	for i, rule := range table.TransferRules {
+		if (rule.FromBusType == "" || rule.FromBusType == fromBusType) && (rule.ToBusType == "" || rule.ToBusType == toBusType) {
+			return &table.TransferRules[i]
+		}
+	}
+	return nil
+}
 
-	// Ensure minimum fare after discount
-	if finalFare < 10.0 {
-		finalFare = 10.0
+// validateRequest validates the fare calculation request
+func (s *FareService) validateRequest(request models.FareRequest) error {
+	hasStopPair := request.RouteID != "" && request.BoardingStop != "" && request.AlightingStop != ""
+	if request.Distance <= 0 && !hasStopPair && (request.StartLocation.NameEn == "" || request.EndLocation.NameEn == "") {
+		return fmt.Errorf("invalid request: must provide either distance, a route and stop pair, or both start and end locations")
 	}
+	return nil
+}
+
+// perKmFare calculates the pre-discount fare from distance and the per-km rate
+func (s *FareService) perKmFare(table *fareTable, distance float64, busType string) utils.Poisha {
+	// Calculate base fare
+	baseFare := distanceCharge(table, distance, busType)
+
+	// Apply minimum fare
+	return max(baseFare, utils.TakaToPoisha(table.MinimumFare))
+}
 
-	return finalFare, baseFare, discountApplied, discountPercentage
+// distanceCharge multiplies the distance by the per-km rate, before any minimum fare
+func distanceCharge(table *fareTable, distance float64, busType string) utils.Poisha {
+	return utils.TakaToPoisha(table.RatesPerKm[busType]).Scale(distance)
 }
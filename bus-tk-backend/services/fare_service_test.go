@@ -0,0 +1,456 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// testDeparture is a Wednesday midday departure, outside every test adjustment
+const testDeparture = "2026-06-10T12:00:00+06:00"
+
+// testFareTable returns a fare table with round numbers: Tk 10/km non-AC, Tk 20/km AC,
+// a Tk 20 minimum fare and a Tk 10 minimum after discounts, quoted to the poisha
+func testFareTable() models.FareTable {
+	return models.FareTable{
+		Version:                  "2024-01",
+		EffectiveFrom:            "2024-01-01",
+		Regulation:               "Test regulation",
+		RatesPerKm:               map[string]float64{"nonAC": 10, "AC": 20},
+		MinimumFare:              20,
+		MinimumFareAfterDiscount: 10,
+	}
+}
+
+// newTestFareService creates a fare service over the given tables, or the test table
+func newTestFareService(t *testing.T, tables ...models.FareTable) *FareService {
+	t.Helper()
+	if len(tables) == 0 {
+		tables = []models.FareTable{testFareTable()}
+	}
+	service, err := NewFareService(tables)
+	if err != nil {
+		t.Fatalf("NewFareService: %v", err)
+	}
+	return service
+}
+
+// checkItemsAddUp checks that a fare's breakdown items add up to the fare
+func checkItemsAddUp(t *testing.T, response *models.FareResponse) {
+	t.Helper()
+	var total float64
+	for _, item := range response.Breakdown.Items {
+		total += item.Amount
+	}
+	if diff := total - response.Fare; diff > 0.001 || diff < -0.001 {
+		t.Errorf("breakdown items add up to %v, fare is %v (%+v)", total, response.Fare, response.Breakdown.Items)
+	}
+	if response.Breakdown.Total != response.Fare {
+		t.Errorf("breakdown total = %v, fare = %v", response.Breakdown.Total, response.Fare)
+	}
+}
+
+func TestCalculateFarePerKm(t *testing.T) {
+	service := newTestFareService(t)
+
+	tests := []struct {
+		name     string
+		request  models.FareRequest
+		distance float64
+		want     float64
+		wantErr  string
+	}{
+		{name: "non-AC", request: models.FareRequest{Distance: 5, BusType: "nonAC"}, distance: 5, want: 50},
+		{name: "AC", request: models.FareRequest{Distance: 5, BusType: "AC"}, distance: 5, want: 100},
+		{name: "bus type defaults to non-AC", request: models.FareRequest{Distance: 3.5}, distance: 3.5, want: 35},
+		{name: "fractional poisha", request: models.FareRequest{Distance: 2.345, BusType: "nonAC"}, distance: 2.345, want: 23.45},
+		{name: "minimum fare", request: models.FareRequest{Distance: 1, BusType: "nonAC"}, distance: 1, want: 20},
+		{name: "unknown bus type", request: models.FareRequest{Distance: 5, BusType: "sleeper"}, distance: 5, wantErr: "unknown bus type"},
+		{name: "no distance or stops", request: models.FareRequest{BusType: "nonAC"}, wantErr: "must provide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.DepartureTime = testDeparture
+			response, err := service.CalculateFare(tt.request, tt.distance)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.Fare != tt.want || response.BaseRate != tt.want {
+				t.Errorf("fare = %v (base %v), want %v", response.Fare, response.BaseRate, tt.want)
+			}
+			if response.FareMethod != string(models.FareMethodPerKm) {
+				t.Errorf("fareMethod = %q, want perKm", response.FareMethod)
+			}
+			checkItemsAddUp(t, response)
+		})
+	}
+}
+
+func TestFareTableSelection(t *testing.T) {
+	older := testFareTable()
+	older.Version, older.EffectiveFrom = "2022-01", "2022-01-01"
+	older.RatesPerKm = map[string]float64{"nonAC": 5, "AC": 8}
+	service := newTestFareService(t, testFareTable(), older)
+
+	tests := []struct {
+		departure   string
+		wantVersion string
+		wantFare    float64
+		wantErr     bool
+	}{
+		{departure: "2023-12-31T23:59:00+06:00", wantVersion: "2022-01", wantFare: 50},
+		{departure: "2024-01-01T00:00:00+06:00", wantVersion: "2024-01", wantFare: 100},
+		{departure: "2023-12-31T18:30:00Z", wantVersion: "2024-01", wantFare: 100}, // Already 2024 in Dhaka
+		{departure: "2021-06-01T12:00:00+06:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.departure, func(t *testing.T) {
+			response, err := service.CalculateFare(models.FareRequest{Distance: 10, DepartureTime: tt.departure}, 10)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got fare table %s, want no table in effect", response.FareTableVersion)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.FareTableVersion != tt.wantVersion || response.Fare != tt.wantFare {
+				t.Errorf("got %s at %v, want %s at %v", response.FareTableVersion, response.Fare, tt.wantVersion, tt.wantFare)
+			}
+		})
+	}
+}
+
+func TestNewFareServiceRejectsInvalidTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(table *models.FareTable)
+	}{
+		{"missing version", func(table *models.FareTable) { table.Version = "" }},
+		{"bad effective date", func(table *models.FareTable) { table.EffectiveFrom = "01/01/2024" }},
+		{"missing AC rate", func(table *models.FareTable) { delete(table.RatesPerKm, "AC") }},
+		{"negative minimum", func(table *models.FareTable) { table.MinimumFare = -1 }},
+		{"unknown rounding mode", func(table *models.FareTable) {
+			table.Rounding = &models.RoundingPolicy{Mode: "banker", Step: 1}
+		}},
+		{"zero rounding step", func(table *models.FareTable) {
+			table.Rounding = &models.RoundingPolicy{Mode: "nearest", Step: 0.001}
+		}},
+		{"adjustment with half a window", func(table *models.FareTable) {
+			table.Adjustments = []models.FareAdjustment{{Name: "Night", StartTime: "23:00", Multiplier: 1.2}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := testFareTable()
+			tt.modify(&table)
+			if _, err := NewFareService([]models.FareTable{table}); err == nil {
+				t.Error("NewFareService accepted an invalid table")
+			}
+		})
+	}
+
+	if _, err := NewFareService([]models.FareTable{testFareTable(), testFareTable()}); err == nil {
+		t.Error("NewFareService accepted duplicate versions")
+	}
+}
+
+// testFareChart prices two stop pairs of one route
+func testFareChart() *models.FareChart {
+	return &models.FareChart{
+		Version: "test",
+		Routes: []models.FareChartRoute{{
+			RouteID: "route-1",
+			Fares: []models.FareChartEntry{
+				{From: "a", To: "b", Fare: 25},
+				{From: "a", To: "c", Fare: 42.5},
+			},
+		}},
+	}
+}
+
+func TestCalculateFareChart(t *testing.T) {
+	service := newTestFareService(t)
+	if err := service.SetFareChart(testFareChart()); err != nil {
+		t.Fatalf("SetFareChart: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		request    models.FareRequest
+		distance   float64
+		want       float64
+		wantMethod models.FareMethod
+	}{
+		{"listed direction", models.FareRequest{RouteID: "route-1", BoardingStop: "a", AlightingStop: "c"}, 0, 42.5, models.FareMethodChart},
+		{"reverse direction", models.FareRequest{RouteID: "route-1", BoardingStop: "c", AlightingStop: "a"}, 0, 42.5, models.FareMethodChart},
+		{"case and spaces ignored", models.FareRequest{RouteID: " Route-1", BoardingStop: "A", AlightingStop: "b "}, 0, 25, models.FareMethodChart},
+		{"chart wins over distance", models.FareRequest{RouteID: "route-1", BoardingStop: "a", AlightingStop: "b", Distance: 10}, 10, 25, models.FareMethodChart},
+		{"unlisted pair falls back to per-km", models.FareRequest{RouteID: "route-1", BoardingStop: "b", AlightingStop: "c", Distance: 4}, 4, 40, models.FareMethodPerKm},
+		{"other route falls back to per-km", models.FareRequest{RouteID: "route-2", BoardingStop: "a", AlightingStop: "b", Distance: 4}, 4, 40, models.FareMethodPerKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.DepartureTime = testDeparture
+			response, err := service.CalculateFare(tt.request, tt.distance)
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.Fare != tt.want || response.FareMethod != string(tt.wantMethod) {
+				t.Errorf("got %v by %s, want %v by %s", response.Fare, response.FareMethod, tt.want, tt.wantMethod)
+			}
+			checkItemsAddUp(t, response)
+		})
+	}
+
+	// A stop pair without a chart entry or a distance cannot be priced
+	_, err := service.CalculateFare(models.FareRequest{RouteID: "route-1", BoardingStop: "b", AlightingStop: "c", DepartureTime: testDeparture}, 0)
+	if err == nil {
+		t.Error("priced an unlisted stop pair without a distance")
+	}
+}
+
+func TestSetFareChartRejectsInvalidEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry models.FareChartEntry
+	}{
+		{"zero fare", models.FareChartEntry{From: "a", To: "b", Fare: 0}},
+		{"negative fare", models.FareChartEntry{From: "a", To: "b", Fare: -5}},
+		{"missing stop", models.FareChartEntry{From: "a", Fare: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestFareService(t)
+			if err := service.SetFareChart(testFareChart()); err != nil {
+				t.Fatalf("SetFareChart: %v", err)
+			}
+
+			chart := testFareChart()
+			chart.Routes[0].Fares = append(chart.Routes[0].Fares, tt.entry)
+			if err := ValidateFareChart(chart); err == nil {
+				t.Error("ValidateFareChart accepted the entry")
+			}
+			if err := service.SetFareChart(chart); err == nil {
+				t.Error("SetFareChart accepted the entry")
+			}
+
+			// The previous chart stays in use
+			response, err := service.CalculateFare(models.FareRequest{RouteID: "route-1", BoardingStop: "a", AlightingStop: "b", DepartureTime: testDeparture}, 0)
+			if err != nil || response.Fare != 25 {
+				t.Errorf("after a rejected chart got %v, %v; want the previous chart fare 25", response, err)
+			}
+		})
+	}
+
+	if err := ValidateFareChart(&models.FareChart{Routes: []models.FareChartRoute{{Fares: testFareChart().Routes[0].Fares}}}); err == nil {
+		t.Error("ValidateFareChart accepted a route without routeId")
+	}
+}
+
+func TestCalculateMultiLegFare(t *testing.T) {
+	table := testFareTable()
+	table.TransferRules = []models.TransferRule{
+		{Name: "AC to AC", FromBusType: "AC", ToBusType: "AC", DiscountPercentage: 50},
+		{Name: "Any transfer", DiscountAmount: 5},
+		{Name: "Never reached", DiscountAmount: 30},
+	}
+	service := newTestFareService(t, table)
+	if err := service.SetDiscounts(&models.DiscountCatalog{Discounts: []models.Discount{
+		{ID: "student", NameEn: "Student", Percentage: 50},
+	}}); err != nil {
+		t.Fatalf("SetDiscounts: %v", err)
+	}
+
+	leg := func(busType string, distance float64) models.FareRequest {
+		return models.FareRequest{BusType: busType, Distance: distance}
+	}
+
+	tests := []struct {
+		name                 string
+		request              models.MultiLegFareRequest
+		wantLegFares         []float64
+		wantRules            []string
+		wantTotal            float64
+		wantBase             float64
+		wantTransferDiscount float64
+		wantPercentage       float64
+	}{
+		{
+			name:                 "first matching rule per transfer",
+			request:              models.MultiLegFareRequest{Legs: []models.FareRequest{leg("nonAC", 5), leg("AC", 5), leg("AC", 5)}},
+			wantLegFares:         []float64{50, 95, 50},
+			wantRules:            []string{"", "Any transfer", "AC to AC"},
+			wantTotal:            195,
+			wantBase:             250,
+			wantTransferDiscount: 55,
+		},
+		{
+			name:                 "journey discount on every leg after transfers",
+			request:              models.MultiLegFareRequest{Legs: []models.FareRequest{leg("nonAC", 5), leg("nonAC", 5)}, DiscountType: "student"},
+			wantLegFares:         []float64{25, 22.5},
+			wantRules:            []string{"", "Any transfer"},
+			wantTotal:            47.5,
+			wantBase:             100,
+			wantTransferDiscount: 5,
+			wantPercentage:       50,
+		},
+		{
+			name:                 "single leg has no transfer",
+			request:              models.MultiLegFareRequest{Legs: []models.FareRequest{leg("AC", 2)}},
+			wantLegFares:         []float64{40},
+			wantRules:            []string{""},
+			wantTotal:            40,
+			wantBase:             40,
+			wantTransferDiscount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.DepartureTime = testDeparture
+			distances := make([]float64, len(tt.request.Legs))
+			for i, leg := range tt.request.Legs {
+				distances[i] = leg.Distance
+			}
+
+			response, err := service.CalculateMultiLegFare(tt.request, distances)
+			if err != nil {
+				t.Fatalf("CalculateMultiLegFare: %v", err)
+			}
+			if len(response.Legs) != len(tt.wantLegFares) {
+				t.Fatalf("got %d legs, want %d", len(response.Legs), len(tt.wantLegFares))
+			}
+			for i, legFare := range response.Legs {
+				if legFare.Fare != tt.wantLegFares[i] || legFare.TransferRule != tt.wantRules[i] {
+					t.Errorf("leg %d: got %v with rule %q, want %v with rule %q", i+1, legFare.Fare, legFare.TransferRule, tt.wantLegFares[i], tt.wantRules[i])
+				}
+				checkItemsAddUp(t, &legFare.FareResponse)
+			}
+			if response.TotalFare != tt.wantTotal || response.TotalBaseFare != tt.wantBase || response.TransferDiscount != tt.wantTransferDiscount {
+				t.Errorf("got total %v, base %v, transfer discount %v; want %v, %v, %v",
+					response.TotalFare, response.TotalBaseFare, response.TransferDiscount, tt.wantTotal, tt.wantBase, tt.wantTransferDiscount)
+			}
+			if response.DiscountPercentage != tt.wantPercentage {
+				t.Errorf("discountPercentage = %v, want %v", response.DiscountPercentage, tt.wantPercentage)
+			}
+		})
+	}
+}
+
+func TestCalculateMultiLegFareRejectsInvalidJourneys(t *testing.T) {
+	service := newTestFareService(t)
+
+	legs := func(n int) []models.FareRequest {
+		legs := make([]models.FareRequest, n)
+		for i := range legs {
+			legs[i] = models.FareRequest{Distance: 3}
+		}
+		return legs
+	}
+	distances := func(n int) []float64 {
+		distances := make([]float64, n)
+		for i := range distances {
+			distances[i] = 3
+		}
+		return distances
+	}
+
+	tests := []struct {
+		name      string
+		request   models.MultiLegFareRequest
+		distances []float64
+		wantErr   string
+	}{
+		{"no legs", models.MultiLegFareRequest{}, nil, "at least one leg"},
+		{"too many legs", models.MultiLegFareRequest{Legs: legs(maxFareLegs + 1)}, distances(maxFareLegs + 1), "at most"},
+		{"missing distances", models.MultiLegFareRequest{Legs: legs(2)}, distances(1), "leg distances"},
+		{"leg with own discount", models.MultiLegFareRequest{Legs: []models.FareRequest{{Distance: 3, DiscountType: "student"}}}, distances(1), "leg 1 has its own discount type"},
+		{"invalid second leg", models.MultiLegFareRequest{Legs: []models.FareRequest{{Distance: 3}, {Distance: 3, BusType: "sleeper"}}}, distances(2), "leg 2: invalid request: unknown bus type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.DepartureTime = testDeparture
+			_, err := service.CalculateMultiLegFare(tt.request, tt.distances)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFareRounding(t *testing.T) {
+	tests := []struct {
+		mode     models.RoundingMode
+		step     float64
+		distance float64
+		want     float64
+	}{
+		{models.RoundingModeNearest, 1, 4.25, 43},
+		{models.RoundingModeNearest, 1, 4.24, 42},
+		{models.RoundingModeCeil, 1, 4.21, 43},
+		{models.RoundingModeFloor, 1, 4.29, 42},
+		{models.RoundingModeNearest, 5, 4.25, 45},
+		{models.RoundingModeNearest, 5, 4.24, 40},
+		{models.RoundingModeCeil, 5, 4.01, 45},
+		{models.RoundingModeFloor, 5, 4.99, 45},
+		{models.RoundingModeCeil, 0.5, 4.21, 42.5},
+		{models.RoundingModeNearest, 5, 4, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v %v km", tt.mode, tt.step, tt.distance), func(t *testing.T) {
+			table := testFareTable()
+			table.Rounding = &models.RoundingPolicy{Mode: string(tt.mode), Step: tt.step}
+			service := newTestFareService(t, table)
+
+			response, err := service.CalculateFare(models.FareRequest{Distance: tt.distance, DepartureTime: testDeparture}, tt.distance)
+			if err != nil {
+				t.Fatalf("CalculateFare: %v", err)
+			}
+			if response.Fare != tt.want {
+				t.Errorf("fare = %v, want %v", response.Fare, tt.want)
+			}
+			checkItemsAddUp(t, response)
+		})
+	}
+}
+
+func TestFareRoundingAfterDiscount(t *testing.T) {
+	table := testFareTable()
+	table.Rounding = &models.RoundingPolicy{Mode: string(models.RoundingModeCeil), Step: 1}
+	service := newTestFareService(t, table)
+	if err := service.SetDiscounts(&models.DiscountCatalog{Discounts: []models.Discount{
+		{ID: "student", NameEn: "Student", Percentage: 50},
+	}}); err != nil {
+		t.Fatalf("SetDiscounts: %v", err)
+	}
+
+	// 42.50 before the discount stays unrounded in baseRate; the 21.25 payable is rounded up
+	response, err := service.CalculateFare(models.FareRequest{Distance: 4.25, DiscountType: "student", DepartureTime: testDeparture}, 4.25)
+	if err != nil {
+		t.Fatalf("CalculateFare: %v", err)
+	}
+	if response.Fare != 22 || response.BaseRate != 42.5 {
+		t.Errorf("got %v (base %v), want 22 (base 42.5)", response.Fare, response.BaseRate)
+	}
+	if response.FareFormatted != "Tk 22" || response.FareFormattedBn != "২২ টাকা" {
+		t.Errorf("formatted = %q / %q", response.FareFormatted, response.FareFormattedBn)
+	}
+	checkItemsAddUp(t, response)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fuzzy matching thresholds
+const (
+	fuzzyMinSimilarity = 0.75 // Minimum similarity for a fuzzy match
+	fuzzyMaxScore      = 0.7  // Fuzzy scores are scaled below the contains tier
+)
+
+// normalizeName lowercases a name and drops spaces, hyphens and punctuation,
+// so "Mirpur 10" and "Mirpur-10" normalize to the same string
+func normalizeName(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// fuzzySimilarity returns how closely the query matches a name, from 0 to 1.
+// The query is compared with the whole name, each word of the name and the
+// name's leading characters, so partially typed names still match.
+func fuzzySimilarity(query, name string) float64 {
+	normalizedQuery := []rune(normalizeName(query))
+	if len(normalizedQuery) == 0 {
+		return 0
+	}
+
+	candidates := []string{normalizeName(name)}
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == ',' || r == '/'
+	}) {
+		candidates = append(candidates, normalizeName(word))
+	}
+
+	best := 0.0
+	for _, candidate := range candidates {
+		candidateRunes := []rune(candidate)
+		best = max(best, similarity(normalizedQuery, candidateRunes))
+
+		// Compare against the leading part of the name with a little slack for insertions
+		if len(candidateRunes) > len(normalizedQuery)+1 {
+			best = max(best, similarity(normalizedQuery, candidateRunes[:len(normalizedQuery)+1]))
+		}
+	}
+	return best
+}
+
+// similarity converts the edit distance between two strings into a 0-1 score
+func similarity(a, b []rune) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
+
+// editDistance returns the optimal string alignment distance between two strings:
+// the number of insertions, deletions, substitutions and adjacent transpositions
+func editDistance(a, b []rune) int {
+	previous2 := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/nominatim"
+)
+
+// GeocodeService resolves addresses that are not in the location list using Nominatim
+type GeocodeService struct {
+	client *nominatim.Client
+}
+
+// NewGeocodeService creates a new geocode service
+func NewGeocodeService(client *nominatim.Client) *GeocodeService {
+	return &GeocodeService{
+		client: client,
+	}
+}
+
+// Geocode finds places matching a free-form address query
+func (s *GeocodeService) Geocode(ctx context.Context, query string, limit int) (*models.GeocodeResponse, error) {
+	places, err := s.client.Search(ctx, query, &nominatim.SearchOptions{Limit: limit, Language: "en"})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.GeocodeResult, 0, len(places))
+	for i := range places {
+		results = append(results, toGeocodeResult(&places[i]))
+	}
+
+	return &models.GeocodeResponse{
+		Results: results,
+		Total:   len(results),
+		Query:   query,
+	}, nil
+}
+
+// ReverseGeocode finds the place at the given coordinates
+func (s *GeocodeService) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.GeocodeResult, error) {
+	place, err := s.client.Reverse(ctx, lat, lon, "en")
+	if err != nil {
+		return nil, err
+	}
+
+	result := toGeocodeResult(place)
+	return &result, nil
+}
+
+// toGeocodeResult converts a Nominatim place into a geocode result
+func toGeocodeResult(place *nominatim.Place) models.GeocodeResult {
+	return models.GeocodeResult{
+		Location: models.Location{
+			NameEn: place.LocalName("en"),
+			NameBn: place.LocalName("bn"),
+			Lat:    float64(place.Lat),
+			Lon:    float64(place.Lon),
+		},
+		DisplayName: place.DisplayName,
+		Type:        place.Type,
+	}
+}
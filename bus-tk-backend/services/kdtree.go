@@ -0,0 +1,182 @@
+package services
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// metersPerDegree is the length of one degree of latitude
+const metersPerDegree = 111320.0
+
+// kdPoint is a point in the k-d tree, projected to planar meters
+type kdPoint struct {
+	x, y  float64
+	index int // Index of the item the point belongs to
+}
+
+// kdNode is a node of the k-d tree
+type kdNode struct {
+	point       kdPoint
+	left, right *kdNode
+	axis        int // 0 splits on x, 1 splits on y
+}
+
+// kdTree is a 2-d tree over lat/lon coordinates for nearest neighbour lookups.
+// Coordinates are projected with an equirectangular projection around the
+// dataset's mean latitude, which is accurate at city scale.
+type kdTree struct {
+	root   *kdNode
+	cosLat float64
+}
+
+// newKDTree builds a k-d tree over the given coordinates; the returned indexes refer to their positions
+func newKDTree(lats, lons []float64) *kdTree {
+	tree := &kdTree{cosLat: 1}
+	if len(lats) == 0 {
+		return tree
+	}
+
+	meanLat := 0.0
+	for _, lat := range lats {
+		meanLat += lat
+	}
+	meanLat /= float64(len(lats))
+	tree.cosLat = math.Cos(meanLat * math.Pi / 180)
+
+	points := make([]kdPoint, len(lats))
+	for i := range lats {
+		x, y := tree.project(lats[i], lons[i])
+		points[i] = kdPoint{x: x, y: y, index: i}
+	}
+	tree.root = buildKDNode(points, 0)
+	return tree
+}
+
+// project converts lat/lon to planar meters
+func (t *kdTree) project(lat, lon float64) (float64, float64) {
+	return lon * metersPerDegree * t.cosLat, lat * metersPerDegree
+}
+
+// buildKDNode recursively builds a balanced subtree by splitting at the median
+func buildKDNode(points []kdPoint, depth int) *kdNode {
+	if len(points) == 0 {
+		return nil
+	}
+
+	axis := depth % 2
+	sort.Slice(points, func(i, j int) bool {
+		if axis == 0 {
+			return points[i].x < points[j].x
+		}
+		return points[i].y < points[j].y
+	})
+
+	median := len(points) / 2
+	return &kdNode{
+		point: points[median],
+		axis:  axis,
+		left:  buildKDNode(points[:median], depth+1),
+		right: buildKDNode(points[median+1:], depth+1),
+	}
+}
+
+// nearest returns the indexes of the k points closest to lat/lon, closest first
+func (t *kdTree) nearest(lat, lon float64, k int) []int {
+	if t.root == nil || k <= 0 {
+		return nil
+	}
+
+	x, y := t.project(lat, lon)
+	candidates := &kdMaxHeap{}
+	t.search(t.root, x, y, k, candidates)
+
+	indexes := make([]int, candidates.Len())
+	for i := len(indexes) - 1; i >= 0; i-- {
+		indexes[i] = heap.Pop(candidates).(kdCandidate).index
+	}
+	return indexes
+}
+
+// withinRadius returns the indexes of all points within radius meters of lat/lon
+func (t *kdTree) withinRadius(lat, lon, radius float64) []int {
+	x, y := t.project(lat, lon)
+	var indexes []int
+	t.searchRadius(t.root, x, y, radius*radius, &indexes)
+	return indexes
+}
+
+// search walks the tree keeping the k best candidates in a bounded max-heap
+func (t *kdTree) search(node *kdNode, x, y float64, k int, candidates *kdMaxHeap) {
+	if node == nil {
+		return
+	}
+
+	dx, dy := node.point.x-x, node.point.y-y
+	distance := dx*dx + dy*dy
+	if candidates.Len() < k {
+		heap.Push(candidates, kdCandidate{index: node.point.index, distance: distance})
+	} else if distance < (*candidates)[0].distance {
+		(*candidates)[0] = kdCandidate{index: node.point.index, distance: distance}
+		heap.Fix(candidates, 0)
+	}
+
+	// Descend into the side containing the query first, then the other side only if it can hold closer points
+	diff := dx
+	if node.axis == 1 {
+		diff = dy
+	}
+	near, far := node.left, node.right
+	if diff < 0 {
+		near, far = node.right, node.left
+	}
+
+	t.search(near, x, y, k, candidates)
+	if candidates.Len() < k || diff*diff < (*candidates)[0].distance {
+		t.search(far, x, y, k, candidates)
+	}
+}
+
+// searchRadius collects all points within the squared radius
+func (t *kdTree) searchRadius(node *kdNode, x, y, radiusSquared float64, indexes *[]int) {
+	if node == nil {
+		return
+	}
+
+	dx, dy := node.point.x-x, node.point.y-y
+	if dx*dx+dy*dy <= radiusSquared {
+		*indexes = append(*indexes, node.point.index)
+	}
+
+	// Only cross the splitting plane if it is within the radius
+	diff := dx
+	if node.axis == 1 {
+		diff = dy
+	}
+	if diff >= 0 || diff*diff <= radiusSquared {
+		t.searchRadius(node.left, x, y, radiusSquared, indexes)
+	}
+	if diff <= 0 || diff*diff <= radiusSquared {
+		t.searchRadius(node.right, x, y, radiusSquared, indexes)
+	}
+}
+
+// kdCandidate is a point found during a nearest neighbour search
+type kdCandidate struct {
+	index    int
+	distance float64 // Squared planar distance
+}
+
+// kdMaxHeap keeps the farthest candidate on top so it can be replaced first
+type kdMaxHeap []kdCandidate
+
+func (h kdMaxHeap) Len() int            { return len(h) }
+func (h kdMaxHeap) Less(i, j int) bool  { return h[i].distance > h[j].distance }
+func (h kdMaxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *kdMaxHeap) Push(x interface{}) { *h = append(*h, x.(kdCandidate)) }
+func (h *kdMaxHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// randomDhakaPoints returns n reproducible coordinates spread over Dhaka
+func randomDhakaPoints(n int) ([]float64, []float64) {
+	random := rand.New(rand.NewSource(1))
+	lats, lons := make([]float64, n), make([]float64, n)
+	for i := range lats {
+		lats[i] = 23.65 + random.Float64()*0.25
+		lons[i] = 90.30 + random.Float64()*0.20
+	}
+	return lats, lons
+}
+
+// squaredDistances returns the projected squared distance from lat/lon to each point
+func squaredDistances(tree *kdTree, lats, lons []float64, lat, lon float64) []float64 {
+	x, y := tree.project(lat, lon)
+	distances := make([]float64, len(lats))
+	for i := range lats {
+		px, py := tree.project(lats[i], lons[i])
+		distances[i] = (px-x)*(px-x) + (py-y)*(py-y)
+	}
+	return distances
+}
+
+func TestKDTreeNearestMatchesBruteForce(t *testing.T) {
+	lats, lons := randomDhakaPoints(500)
+	tree := newKDTree(lats, lons)
+	queries := [][2]float64{{23.81, 90.41}, {23.65, 90.30}, {23.90, 90.50}, {23.70, 90.45}, {24.10, 90.10}}
+
+	for _, k := range []int{1, 5, 20, 500, 600} {
+		for _, query := range queries {
+			distances := squaredDistances(tree, lats, lons, query[0], query[1])
+			want := slices.Clone(distances)
+			sort.Float64s(want)
+			want = want[:min(k, len(want))]
+
+			got := tree.nearest(query[0], query[1], k)
+			if len(got) != len(want) {
+				t.Fatalf("k=%d at %v: got %d points, want %d", k, query, len(got), len(want))
+			}
+			for i, index := range got {
+				if distances[index] != want[i] {
+					t.Errorf("k=%d at %v: result %d is at %v, want %v", k, query, i, distances[index], want[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestKDTreeWithinRadiusMatchesBruteForce(t *testing.T) {
+	lats, lons := randomDhakaPoints(500)
+	tree := newKDTree(lats, lons)
+
+	for _, radius := range []float64{0, 250, 1000, 5000} {
+		distances := squaredDistances(tree, lats, lons, 23.78, 90.40)
+		var want []int
+		for i, distance := range distances {
+			if distance <= radius*radius {
+				want = append(want, i)
+			}
+		}
+
+		got := tree.withinRadius(23.78, 90.40, radius)
+		sort.Ints(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("radius %v: got %d points, want %d", radius, len(got), len(want))
+		}
+	}
+}
+
+func TestKDTreeEdgeCases(t *testing.T) {
+	empty := newKDTree(nil, nil)
+	if got := empty.nearest(23.8, 90.4, 3); len(got) != 0 {
+		t.Errorf("empty tree returned %v", got)
+	}
+	if got := empty.withinRadius(23.8, 90.4, 1000); len(got) != 0 {
+		t.Errorf("empty tree returned %v within the radius", got)
+	}
+
+	// Duplicate coordinates are all returned
+	tree := newKDTree([]float64{23.8, 23.8, 23.8, 23.9}, []float64{90.4, 90.4, 90.4, 90.4})
+	got := tree.nearest(23.8, 90.4, 3)
+	sort.Ints(got)
+	if !slices.Equal(got, []int{0, 1, 2}) {
+		t.Errorf("nearest to duplicates = %v, want [0 1 2]", got)
+	}
+	if got := tree.nearest(23.8, 90.4, 0); len(got) != 0 {
+		t.Errorf("k=0 returned %v", got)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+var (
+	// ErrLocationNotFound is returned when editing a location that does not exist
+	ErrLocationNotFound = errors.New("location not found")
+	// ErrLocationsNotSaved is returned when an edit could not be saved to the store
+	ErrLocationsNotSaved = errors.New("failed to save locations")
+)
+
+// duplicateLocationMeters is how close a location with the same name must be to count as a duplicate
+const duplicateLocationMeters = 500
+
+// dhakaBounds is the area new and edited locations must lie in: Dhaka city with
+// Savar, Tongi, Purbachal and Narayanganj on its edges
+var dhakaBounds = struct {
+	minLat, maxLat, minLon, maxLon float64
+}{minLat: 23.55, maxLat: 24.05, minLon: 90.15, maxLon: 90.65}
+
+// CreateLocation validates and adds a location. Without an ID it is given a slug
+// of its English name.
+func (s *LocationService) CreateLocation(location models.Location) (*models.Location, error) {
+	location = normalizeLocation(location)
+	if location.ID != "" && slugify(location.ID) != location.ID {
+		return nil, fmt.Errorf("invalid request: id must be lowercase letters, digits and hyphens")
+	}
+
+	var created models.Location
+	err := s.editLocations(func(locations []models.Location) ([]models.Location, error) {
+		if err := validateLocation(locations, location, -1); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+		if _, err := AssignLocationIDs(locations); err != nil {
+			return nil, fmt.Errorf("invalid request: %v", err)
+		}
+		created = locations[len(locations)-1]
+		return locations, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateLocation replaces the names and coordinates of a location; its ID never changes
+func (s *LocationService) UpdateLocation(id string, location models.Location) (*models.Location, error) {
+	location = normalizeLocation(location)
+	if location.ID != "" && location.ID != id {
+		return nil, fmt.Errorf("invalid request: a location's id cannot be changed")
+	}
+	location.ID = id
+
+	err := s.editLocations(func(locations []models.Location) ([]models.Location, error) {
+		index := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == id })
+		if index < 0 {
+			return nil, ErrLocationNotFound
+		}
+		if err := validateLocation(locations, location, index); err != nil {
+			return nil, err
+		}
+		locations[index] = location
+		return locations, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// DeleteLocation removes a location
+func (s *LocationService) DeleteLocation(id string) error {
+	return s.editLocations(func(locations []models.Location) ([]models.Location, error) {
+		index := slices.IndexFunc(locations, func(l models.Location) bool { return l.ID == id })
+		if index < 0 {
+			return nil, ErrLocationNotFound
+		}
+		return slices.Delete(locations, index, index+1), nil
+	})
+}
+
+// editLocations applies an edit to a copy of the dataset, saves the result to the
+// store and swaps it in with fresh indexes. Searches use the previous dataset until
+// the swap, so they never wait for the indexes to be rebuilt.
+func (s *LocationService) editLocations(edit func([]models.Location) ([]models.Location, error)) error {
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+
+	s.mu.RLock()
+	locations := slices.Clone(s.locations)
+	s.mu.RUnlock()
+
+	locations, err := edit(locations)
+	if err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.SaveLocations(locations); err != nil {
+			log.Printf("Error saving locations: %v", err)
+			return ErrLocationsNotSaved
+		}
+		s.loadedModTime = s.storeModTime()
+	}
+
+	s.setLocations(locations)
+	s.generation++
+	return nil
+}
+
+// normalizeLocation trims the names and ID of a location
+func normalizeLocation(location models.Location) models.Location {
+	location.ID = strings.TrimSpace(location.ID)
+	location.NameEn = strings.Join(strings.Fields(location.NameEn), " ")
+	location.NameBn = strings.Join(strings.Fields(location.NameBn), " ")
+	return location
+}
+
+// validateLocation checks a location's names and coordinates and that it does not
+// duplicate another location. skip is the position of the location being replaced, or -1.
+func validateLocation(locations []models.Location, location models.Location, skip int) error {
+	if err := ValidateLocationFields(location); err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+	if location.Lat < dhakaBounds.minLat || location.Lat > dhakaBounds.maxLat ||
+		location.Lon < dhakaBounds.minLon || location.Lon > dhakaBounds.maxLon {
+		return fmt.Errorf("invalid request: coordinates %.6f, %.6f are outside Dhaka", location.Lat, location.Lon)
+	}
+
+	for i, other := range locations {
+		if i == skip {
+			continue
+		}
+		sameName := strings.EqualFold(other.NameEn, location.NameEn) || other.NameBn == location.NameBn
+		if sameName && utils.HaversineMeters(other.Lat, other.Lon, location.Lat, location.Lon) <= duplicateLocationMeters {
+			return fmt.Errorf("invalid request: duplicates location %q within %d m", other.ID, duplicateLocationMeters)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+)
+
+func TestCreateLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location models.Location
+		wantID   string
+		wantErr  bool
+	}{
+		{name: "new location", location: models.Location{NameEn: "Farmgate", NameBn: "ফার্মগেট", Lat: 23.7561, Lon: 90.3872}, wantID: "farmgate"},
+		{name: "given id", location: models.Location{ID: "farm-gate", NameEn: "Farmgate", NameBn: "ফার্মগেট", Lat: 23.7561, Lon: 90.3872}, wantID: "farm-gate"},
+		{name: "id that is not a slug", location: models.Location{ID: "Farm Gate", NameEn: "Farmgate", NameBn: "ফার্মগেট", Lat: 23.7561, Lon: 90.3872}, wantErr: true},
+		{name: "south of Dhaka", location: models.Location{NameEn: "Chittagong", NameBn: "চট্টগ্রাম", Lat: 22.3569, Lon: 91.7832}, wantErr: true},
+		{name: "east of Dhaka", location: models.Location{NameEn: "Narsingdi", NameBn: "নরসিংদী", Lat: 23.9200, Lon: 90.7200}, wantErr: true},
+		{name: "on the edge of the bounds", location: models.Location{NameEn: "Tongi", NameBn: "টঙ্গী", Lat: 23.8900, Lon: 90.6500}, wantID: "tongi"},
+		{name: "same English name within 500 m", location: models.Location{NameEn: "mirpur  10", NameBn: "মিরপুর দশ", Lat: 23.8080, Lon: 90.3690}, wantErr: true},
+		{name: "same Bengali name within 500 m", location: models.Location{NameEn: "Mirpur Ten", NameBn: "মিরপুর ১০", Lat: 23.8080, Lon: 90.3690}, wantErr: true},
+		{name: "same name far away", location: models.Location{NameEn: "Mirpur 10", NameBn: "মিরপুর ১০", Lat: 23.7600, Lon: 90.3690}, wantID: "mirpur-10-2"},
+		{name: "other name nearby", location: models.Location{NameEn: "Mirpur 10 Circle", NameBn: "মিরপুর ১০ গোলচত্বর", Lat: 23.8070, Lon: 90.3688}, wantID: "mirpur-10-circle"},
+		{name: "missing Bengali name", location: models.Location{NameEn: "Farmgate", Lat: 23.7561, Lon: 90.3872}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestLocationService(t, testLocations())
+			created, err := service.CreateLocation(tt.location)
+			if tt.wantErr {
+				if err == nil || errors.Is(err, ErrLocationsNotSaved) {
+					t.Errorf("err = %v, want the location rejected", err)
+				}
+				if total := service.GetTotalLocations(); total != 2 {
+					t.Errorf("%d locations after a rejected create, want 2", total)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateLocation: %v", err)
+			}
+			if created.ID != tt.wantID {
+				t.Errorf("id = %q, want %q", created.ID, tt.wantID)
+			}
+			if total := service.GetTotalLocations(); total != 3 {
+				t.Errorf("%d locations after a create, want 3", total)
+			}
+		})
+	}
+}
+
+func TestUpdateLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		location models.Location
+		wantErr  error // The specific error expected, if any
+		wantFail bool
+	}{
+		{name: "move within Dhaka", id: "motijheel", location: models.Location{NameEn: "Motijheel", NameBn: "মতিঝিল", Lat: 23.7335, Lon: 90.4180}},
+		{name: "same id in the body", id: "motijheel", location: models.Location{ID: "motijheel", NameEn: "Motijheel C/A", NameBn: "মতিঝিল", Lat: 23.7330, Lon: 90.4172}},
+		{name: "changed id", id: "motijheel", location: models.Location{ID: "motijheel-ca", NameEn: "Motijheel", NameBn: "মতিঝিল", Lat: 23.7330, Lon: 90.4172}, wantFail: true},
+		{name: "moved outside Dhaka", id: "motijheel", location: models.Location{NameEn: "Motijheel", NameBn: "মতিঝিল", Lat: 23.4607, Lon: 91.1809}, wantFail: true},
+		{name: "renamed onto a nearby location", id: "motijheel", location: models.Location{NameEn: "Mirpur 10", NameBn: "মতিঝিল", Lat: 23.8070, Lon: 90.3690}, wantFail: true},
+		{name: "unknown id", id: "gulshan-1", location: models.Location{NameEn: "Gulshan 1", NameBn: "গুলশান ১", Lat: 23.7806, Lon: 90.4163}, wantErr: ErrLocationNotFound, wantFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestLocationService(t, testLocations())
+			updated, err := service.UpdateLocation(tt.id, tt.location)
+			if tt.wantFail {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("err = %v, want the update rejected (%v)", err, tt.wantErr)
+				}
+				if location := service.GetLocationByID("motijheel"); location == nil || location.Lat != 23.7330 || location.NameEn != "Motijheel" {
+					t.Errorf("motijheel = %+v after a rejected update, want it unchanged", location)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateLocation: %v", err)
+			}
+			if updated.ID != tt.id {
+				t.Errorf("id = %q, want it kept as %q", updated.ID, tt.id)
+			}
+			if location := service.GetLocationByID(tt.id); location == nil || *location != *updated {
+				t.Errorf("stored %+v, want %+v", location, updated)
+			}
+		})
+	}
+}
+
+func TestDeleteLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		wantErr   error
+		wantTotal int
+	}{
+		{name: "existing location", id: "motijheel", wantTotal: 1},
+		{name: "unknown id", id: "gulshan-1", wantErr: ErrLocationNotFound, wantTotal: 2},
+		{name: "empty id", id: "", wantErr: ErrLocationNotFound, wantTotal: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, path := newTestLocationService(t, testLocations())
+			if err := service.DeleteLocation(tt.id); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if total := service.GetTotalLocations(); total != tt.wantTotal {
+				t.Errorf("%d locations in memory, want %d", total, tt.wantTotal)
+			}
+			saved, err := storage.NewJSONStore(path).LoadLocations()
+			if err != nil {
+				t.Fatalf("LoadLocations: %v", err)
+			}
+			if len(saved) != tt.wantTotal {
+				t.Errorf("%d locations saved, want %d", len(saved), tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestEditLocationsNotSaved(t *testing.T) {
+	locations := testLocations()
+	locations[0].ID, locations[1].ID = "mirpur-10", "motijheel"
+	service, err := NewLocationService(&memoryLocationStore{locations: locations, saveErr: errors.New("disk full")})
+	if err != nil {
+		t.Fatalf("NewLocationService: %v", err)
+	}
+
+	edits := []struct {
+		name string
+		edit func() error
+	}{
+		{"create", func() error {
+			_, err := service.CreateLocation(models.Location{NameEn: "Farmgate", NameBn: "ফার্মগেট", Lat: 23.7561, Lon: 90.3872})
+			return err
+		}},
+		{"update", func() error {
+			_, err := service.UpdateLocation("motijheel", models.Location{NameEn: "Motijheel", NameBn: "মতিঝিল", Lat: 23.7335, Lon: 90.4180})
+			return err
+		}},
+		{"delete", func() error { return service.DeleteLocation("motijheel") }},
+	}
+
+	for _, tt := range edits {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.edit(); !errors.Is(err, ErrLocationsNotSaved) {
+				t.Errorf("err = %v, want ErrLocationsNotSaved", err)
+			}
+			if total := service.GetTotalLocations(); total != 2 {
+				t.Errorf("%d locations in memory, want the unsaved edit dropped", total)
+			}
+			if location := service.GetLocationByID("motijheel"); location == nil || location.Lat != 23.7330 {
+				t.Errorf("motijheel = %+v, want it unchanged", location)
+			}
+		})
+	}
+}
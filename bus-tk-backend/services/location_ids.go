@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// AssignLocationIDs gives every location without an ID a unique slug derived from its
+// English name ("Mirpur-10" becomes "mirpur-10", a second one "mirpur-10-2").
+// Existing IDs are kept. It returns the number of IDs assigned.
+func AssignLocationIDs(locations []models.Location) (int, error) {
+	taken := make(map[string]bool, len(locations))
+	for _, location := range locations {
+		if location.ID == "" {
+			continue
+		}
+		if taken[location.ID] {
+			return 0, fmt.Errorf("duplicate location id %q", location.ID)
+		}
+		taken[location.ID] = true
+	}
+
+	assigned := 0
+	for i := range locations {
+		if locations[i].ID != "" {
+			continue
+		}
+
+		base := slugify(locations[i].NameEn)
+		id := base
+		for suffix := 2; taken[id]; suffix++ {
+			id = base + "-" + strconv.Itoa(suffix)
+		}
+
+		locations[i].ID = id
+		taken[id] = true
+		assigned++
+	}
+
+	return assigned, nil
+}
+
+// slugify converts a name into a lowercase, hyphen-separated identifier
+func slugify(name string) string {
+	var builder strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+
+	if builder.Len() == 0 {
+		return "location"
+	}
+	return builder.String()
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Mirpur 10", "mirpur-10"},
+		{"  Mirpur-10 ", "mirpur-10"},
+		{"Motijheel C/A", "motijheel-c-a"},
+		{"Farmgate (Tejgaon)", "farmgate-tejgaon"},
+		{"Shahbag--Square", "shahbag-square"},
+		{"Café Uttara", "caf-uttara"},
+		{"Gulshan ১", "gulshan"},
+		{"মিরপুর ১০", "location"},
+		{"", "location"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slugify(tt.name); got != tt.want {
+				t.Errorf("slugify(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignLocationIDs(t *testing.T) {
+	location := func(id, nameEn string) models.Location {
+		return models.Location{ID: id, NameEn: nameEn}
+	}
+
+	tests := []struct {
+		name         string
+		locations    []models.Location
+		want         []string
+		wantAssigned int
+	}{
+		{
+			name:         "slugs of the English names",
+			locations:    []models.Location{location("", "Mirpur 10"), location("", "Motijheel")},
+			want:         []string{"mirpur-10", "motijheel"},
+			wantAssigned: 2,
+		},
+		{
+			name:         "colliding slugs are numbered",
+			locations:    []models.Location{location("", "Mirpur 10"), location("", "Mirpur-10"), location("", "mirpur 10")},
+			want:         []string{"mirpur-10", "mirpur-10-2", "mirpur-10-3"},
+			wantAssigned: 3,
+		},
+		{
+			name:         "existing IDs are kept and skipped",
+			locations:    []models.Location{location("", "Mirpur 10"), location("mirpur-10", "Mirpur Ten"), location("mirpur-10-2", "Mirpur")},
+			want:         []string{"mirpur-10-3", "mirpur-10", "mirpur-10-2"},
+			wantAssigned: 1,
+		},
+		{
+			name:         "Bengali-only names",
+			locations:    []models.Location{location("", "মিরপুর ১০"), location("", "মতিঝিল"), location("location", "Location")},
+			want:         []string{"location-2", "location-3", "location"},
+			wantAssigned: 2,
+		},
+		{
+			name:      "nothing to assign",
+			locations: []models.Location{location("gabtoli", "Gabtoli")},
+			want:      []string{"gabtoli"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assigned, err := AssignLocationIDs(tt.locations)
+			if err != nil {
+				t.Fatalf("AssignLocationIDs: %v", err)
+			}
+			var got []string
+			for _, location := range tt.locations {
+				got = append(got, location.ID)
+			}
+			if !slices.Equal(got, tt.want) || assigned != tt.wantAssigned {
+				t.Errorf("assigned %d IDs %v, want %d IDs %v", assigned, got, tt.wantAssigned, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignLocationIDsRejectsDuplicates(t *testing.T) {
+	locations := []models.Location{
+		{NameEn: "Mirpur 10"},
+		{ID: "motijheel", NameEn: "Motijheel"},
+		{ID: "motijheel", NameEn: "Motijheel C/A"},
+	}
+
+	if _, err := AssignLocationIDs(locations); err == nil {
+		t.Fatal("AssignLocationIDs accepted a duplicate ID")
+	}
+	// Nothing is assigned when the dataset is rejected
+	if locations[0].ID != "" {
+		t.Errorf("assigned %q to a rejected dataset", locations[0].ID)
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+)
+
+// errReloadRaced is returned when another edit or reload swapped in a dataset while
+// a reload was reading the store
+var errReloadRaced = errors.New("locations changed during the reload")
+
+// ReloadLocations reads the dataset from the store again and swaps it in once it
+// has been validated and indexed. Searches keep using the current dataset until
+// then, edits are only held up for the swap, and an invalid dataset leaves the
+// current one in place.
+func (s *LocationService) ReloadLocations() (*models.LocationReloadResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("locations are not backed by a store")
+	}
+
+	for {
+		response, err := s.reloadLocations()
+		// An edit saved meanwhile is in the store, so read it again rather than lose it
+		if !errors.Is(err, errReloadRaced) {
+			return response, err
+		}
+	}
+}
+
+// reloadLocations reads, validates and indexes the stored dataset, then swaps it in
+// unless another dataset was swapped in since the read began
+func (s *LocationService) reloadLocations() (*models.LocationReloadResponse, error) {
+	s.editMu.Lock()
+	generation := s.generation
+	s.editMu.Unlock()
+
+	modTime := s.storeModTime()
+	locations, err := s.store.LoadLocations()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDataset(locations); err != nil {
+		return nil, err
+	}
+	assigned, err := AssignLocationIDs(locations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dataset: %v", err)
+	}
+	indexes := indexLocations(locations)
+
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+	if s.generation != generation {
+		return nil, errReloadRaced
+	}
+
+	// Persist the IDs given to new locations so they never change
+	if assigned > 0 {
+		if err := s.store.SaveLocations(locations); err != nil {
+			log.Printf("Error saving location IDs: %v", err)
+			return nil, ErrLocationsNotSaved
+		}
+		modTime = s.storeModTime()
+	}
+
+	previous := s.GetTotalLocations()
+	s.swapLocations(locations, indexes)
+	s.loadedModTime = modTime
+	s.generation++
+	log.Printf("Reloaded %d locations (previously %d)", len(locations), previous)
+
+	return &models.LocationReloadResponse{
+		Total:       len(locations),
+		Previous:    previous,
+		AssignedIDs: assigned,
+	}, nil
+}
+
+// CanWatchLocations reports whether the store can change outside the server and
+// can therefore be watched, as a JSON file can and a bolt database cannot
+func (s *LocationService) CanWatchLocations() bool {
+	_, ok := s.store.(storage.Watchable)
+	return ok
+}
+
+// WatchLocations polls the store every interval and reloads the locations when it
+// has changed, until the context is cancelled. Stores that cannot change outside
+// the server are not watched, and a warning is logged instead.
+func (s *LocationService) WatchLocations(ctx context.Context, interval time.Duration) {
+	if !s.CanWatchLocations() {
+		log.Printf("⚠️ The %T location store is only changed through the admin API and is not watched", s.store)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.editMu.Lock()
+		changed := !s.storeModTime().Equal(s.loadedModTime)
+		s.editMu.Unlock()
+		if !changed {
+			continue
+		}
+		if _, err := s.ReloadLocations(); err != nil {
+			// Remember the broken version so it is not retried until the file changes again
+			log.Printf("⚠️ Location reload failed, keeping the current dataset: %v", err)
+			s.editMu.Lock()
+			s.loadedModTime = s.storeModTime()
+			s.editMu.Unlock()
+		}
+	}
+}
+
+// storeModTime returns when the store last changed, or the zero time for stores
+// that cannot change outside the server
+func (s *LocationService) storeModTime() time.Time {
+	watchable, ok := s.store.(storage.Watchable)
+	if !ok {
+		return time.Time{}
+	}
+	modTime, err := watchable.ModTime()
+	if err != nil {
+		return time.Time{}
+	}
+	return modTime
+}
+
+// validateDataset checks that a whole dataset is usable before it replaces the current one
+func validateDataset(locations []models.Location) error {
+	if len(locations) == 0 {
+		return fmt.Errorf("invalid dataset: no locations")
+	}
+	return ValidateLocations(locations)
+}
+
+// ValidateLocations checks the names and coordinates of every location in a dataset.
+// The server, the admin API and the GTFS importer share it so they accept the same data.
+func ValidateLocations(locations []models.Location) error {
+	for i, location := range locations {
+		if err := ValidateLocationFields(location); err != nil {
+			return fmt.Errorf("invalid dataset: location %d (%s): %v", i+1, location.ID, err)
+		}
+	}
+	return nil
+}
+
+// ValidateLocationFields checks that a location has both names and valid coordinates
+func ValidateLocationFields(location models.Location) error {
+	if strings.TrimSpace(location.NameEn) == "" || strings.TrimSpace(location.NameBn) == "" {
+		return fmt.Errorf("nameEn and nameBn are required")
+	}
+	if !validCoordinates(location.Lat, location.Lon) {
+		return fmt.Errorf("invalid coordinates %v, %v", location.Lat, location.Lon)
+	}
+	return nil
+}
+
+// validCoordinates reports whether a latitude and longitude are numbers within range
+func validCoordinates(lat, lon float64) bool {
+	return !math.IsNaN(lat) && !math.IsNaN(lon) && math.Abs(lat) <= 90 && math.Abs(lon) <= 180
+}
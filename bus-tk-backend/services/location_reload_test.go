@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+)
+
+// testLocations returns two Dhaka locations without IDs
+func testLocations() []models.Location {
+	return []models.Location{
+		{NameEn: "Mirpur 10", NameBn: "মিরপুর ১০", Lat: 23.8069, Lon: 90.3687},
+		{NameEn: "Motijheel", NameBn: "মতিঝিল", Lat: 23.7330, Lon: 90.4172},
+	}
+}
+
+// newTestLocationService writes the locations to a JSON file and loads a service over it
+func newTestLocationService(t *testing.T, locations []models.Location) (*LocationService, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "locations.json")
+	if err := storage.NewJSONStore(path).SaveLocations(locations); err != nil {
+		t.Fatalf("SaveLocations: %v", err)
+	}
+	service, err := NewLocationService(storage.NewJSONStore(path))
+	if err != nil {
+		t.Fatalf("NewLocationService: %v", err)
+	}
+	return service, path
+}
+
+// memoryLocationStore keeps locations in memory. A non-nil saveErr fails every save,
+// and afterLoad runs once, after the next load has read the locations.
+type memoryLocationStore struct {
+	locations []models.Location
+	saveErr   error
+	afterLoad func()
+}
+
+func (s *memoryLocationStore) LoadLocations() ([]models.Location, error) {
+	locations := slices.Clone(s.locations)
+	if afterLoad := s.afterLoad; afterLoad != nil {
+		s.afterLoad = nil
+		afterLoad()
+	}
+	return locations, nil
+}
+
+func (s *memoryLocationStore) SaveLocations(locations []models.Location) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.locations = slices.Clone(locations)
+	return nil
+}
+
+func (s *memoryLocationStore) Close() error { return nil }
+
+// writeFile replaces a file's content and moves its modification time forward,
+// as file systems with coarse timestamps may not register a quick rewrite
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	modTime := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+func TestReloadLocationsRejectsBadData(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not JSON", `[{"nameEn": "Mirpur"`},
+		{"empty dataset", `[]`},
+		{"missing Bengali name", `[{"nameEn": "Mirpur", "lat": 23.8, "lon": 90.36}]`},
+		{"invalid coordinates", `[{"nameEn": "Mirpur", "nameBn": "মিরপুর", "lat": 123.8, "lon": 90.36}]`},
+		{"duplicate IDs", `[{"id": "mirpur", "nameEn": "Mirpur", "nameBn": "মিরপুর", "lat": 23.8, "lon": 90.36},
+			{"id": "mirpur", "nameEn": "Mirpur 2", "nameBn": "মিরপুর ২", "lat": 23.81, "lon": 90.36}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, path := newTestLocationService(t, testLocations())
+			writeFile(t, path, tt.content)
+
+			if _, err := service.ReloadLocations(); err == nil {
+				t.Fatal("ReloadLocations accepted the dataset")
+			}
+
+			// The previous dataset and its indexes stay in use
+			if total := service.GetTotalLocations(); total != 2 {
+				t.Errorf("total = %d, want the previous 2", total)
+			}
+			if results := service.SearchLocations("motijheel", "en", 5); results.Total != 1 {
+				t.Errorf("search found %d results in the previous dataset, want 1", results.Total)
+			}
+			if service.GetLocationByID("mirpur-10") == nil {
+				t.Error("previous location mirpur-10 is gone")
+			}
+		})
+	}
+}
+
+func TestReloadLocations(t *testing.T) {
+	service, path := newTestLocationService(t, testLocations())
+
+	locations, err := storage.NewJSONStore(path).LoadLocations()
+	if err != nil {
+		t.Fatalf("LoadLocations: %v", err)
+	}
+	locations = append(locations, models.Location{NameEn: "Gulshan 1", NameBn: "গুলশান ১", Lat: 23.7806, Lon: 90.4170})
+	if err := storage.NewJSONStore(path).SaveLocations(locations); err != nil {
+		t.Fatalf("SaveLocations: %v", err)
+	}
+
+	response, err := service.ReloadLocations()
+	if err != nil {
+		t.Fatalf("ReloadLocations: %v", err)
+	}
+	if *response != (models.LocationReloadResponse{Total: 3, Previous: 2, AssignedIDs: 1}) {
+		t.Errorf("response = %+v", *response)
+	}
+	if results := service.SearchLocations("gulshan", "en", 5); results.Total != 1 {
+		t.Errorf("search found %d results for the new location, want 1", results.Total)
+	}
+	if nearest := service.NearestLocations(23.7806, 90.4170, 1); len(nearest.Locations) != 1 || nearest.Locations[0].ID != "gulshan-1" {
+		t.Errorf("nearest = %+v, want gulshan-1", nearest.Locations)
+	}
+
+	// The assigned ID was saved so it stays the same on the next reload
+	saved, err := storage.NewJSONStore(path).LoadLocations()
+	if err != nil {
+		t.Fatalf("LoadLocations: %v", err)
+	}
+	if saved[2].ID != "gulshan-1" {
+		t.Errorf("saved ID = %q, want gulshan-1", saved[2].ID)
+	}
+}
+
+func TestReloadLocationsKeepsConcurrentEdits(t *testing.T) {
+	store := &memoryLocationStore{locations: testLocations()}
+	service, err := NewLocationService(store)
+	if err != nil {
+		t.Fatalf("NewLocationService: %v", err)
+	}
+
+	// An admin adds a location after the reload has read the store
+	store.afterLoad = func() {
+		if _, err := service.CreateLocation(models.Location{NameEn: "Farmgate", NameBn: "ফার্মগেট", Lat: 23.7561, Lon: 90.3872}); err != nil {
+			t.Errorf("CreateLocation: %v", err)
+		}
+	}
+	response, err := service.ReloadLocations()
+	if err != nil {
+		t.Fatalf("ReloadLocations: %v", err)
+	}
+	if response.Total != 3 || service.GetLocationByID("farmgate") == nil {
+		t.Errorf("reloaded %+v, want the added location kept", *response)
+	}
+}
+
+func TestReloadLocationsNotSaved(t *testing.T) {
+	store := &memoryLocationStore{locations: testLocations()}
+	service, err := NewLocationService(store)
+	if err != nil {
+		t.Fatalf("NewLocationService: %v", err)
+	}
+
+	// A new location needs an ID, which cannot be saved
+	store.locations = append(store.locations, models.Location{NameEn: "Gulshan 1", NameBn: "গুলশান ১", Lat: 23.7806, Lon: 90.4170})
+	store.saveErr = errors.New("disk full")
+	if _, err := service.ReloadLocations(); !errors.Is(err, ErrLocationsNotSaved) {
+		t.Errorf("err = %v, want ErrLocationsNotSaved", err)
+	}
+	if total := service.GetTotalLocations(); total != 2 {
+		t.Errorf("total = %d, want the previous 2", total)
+	}
+}
+
+func TestWatchLocations(t *testing.T) {
+	service, path := newTestLocationService(t, testLocations())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.WatchLocations(ctx, 10*time.Millisecond)
+
+	// A broken edit is ignored, and the next good one is picked up
+	writeFile(t, path, `[`)
+	time.Sleep(50 * time.Millisecond)
+	if total := service.GetTotalLocations(); total != 2 {
+		t.Fatalf("total = %d after a broken edit, want the previous 2", total)
+	}
+
+	writeFile(t, path, `[{"nameEn": "Gulshan 1", "nameBn": "গুলশান ১", "lat": 23.7806, "lon": 90.4170}]`)
+	deadline := time.Now().Add(2 * time.Second)
+	for service.GetTotalLocations() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("total = %d, the edit was not reloaded", service.GetTotalLocations())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -1,52 +1,116 @@
 package services
 
 import (
-	"encoding/json"
+	"fmt"
 	"log"
-	"os"
+	"math"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/storage"
+	"github.com/spectrum/bus-tk-backend/utils"
 )
 
 // LocationService handles location-related business logic with efficient search
 type LocationService struct {
-	locations   []models.Location
-	mu          sync.RWMutex
-	initialized bool
+	store         storage.LocationStore // Where edits are saved; nil for in-memory datasets
+	locations     []models.Location
+	byID          map[string]int // Location ID to position in locations
+	searchIndex   *searchIndex   // Name index for search
+	spatialIndex  *kdTree        // Nearest-neighbour index over the location coordinates
+	mu            sync.RWMutex   // Guards the dataset and indexes above
+	editMu        sync.Mutex     // Serializes edits and reloads
+	loadedModTime time.Time      // Store modification time of the dataset in memory, guarded by editMu
+	generation    uint64         // Counts the datasets swapped in by edits and reloads, guarded by editMu
 }
 
-// NewLocationService creates a new location service
-func NewLocationService() *LocationService {
+// NewLocationService creates a new location service over the locations in a store
+func NewLocationService(store storage.LocationStore) (*LocationService, error) {
+	locations, err := store.LoadLocations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateLocations(locations); err != nil {
+		return nil, err
+	}
+
+	// Give new locations a stable ID and persist it so it never changes
+	assigned, err := AssignLocationIDs(locations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign location IDs: %v", err)
+	}
+	if assigned > 0 {
+		if err := store.SaveLocations(locations); err != nil {
+			return nil, fmt.Errorf("failed to save location IDs: %v", err)
+		}
+		log.Printf("Assigned IDs to %d locations and saved them", assigned)
+	}
+
+	service := &LocationService{store: store}
+	service.loadedModTime = service.storeModTime()
+	service.setLocations(locations)
+	log.Printf("Loaded %d locations into memory", len(locations))
+	return service, nil
+}
+
+// NewLocationServiceFromLocations creates a location service over an in-memory dataset
+func NewLocationServiceFromLocations(locations []models.Location) (*LocationService, error) {
+	if _, err := AssignLocationIDs(locations); err != nil {
+		return nil, err
+	}
+
 	service := &LocationService{}
-	service.initializeLocations()
-	return service
+	service.setLocations(locations)
+	return service, nil
 }
 
-// initializeLocations loads and caches all locations in memory
-func (s *LocationService) initializeLocations() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// locationIndexes are the lookups built over a dataset
+type locationIndexes struct {
+	byID         map[string]int
+	searchIndex  *searchIndex
+	spatialIndex *kdTree
+}
 
-	if s.initialized {
-		return
+// indexLocations builds the ID, search and spatial indexes for a dataset
+func indexLocations(locations []models.Location) locationIndexes {
+	byID := make(map[string]int, len(locations))
+	for i, location := range locations {
+		byID[location.ID] = i
 	}
-
-	jsonData, err := os.ReadFile("data/dhaka_areas.json")
-	if err != nil {
-		log.Fatalf("Error reading file: %v", err)
+	return locationIndexes{
+		byID:         byID,
+		searchIndex:  newSearchIndex(locations),
+		spatialIndex: buildSpatialIndex(locations),
 	}
+}
 
-	var locations []models.Location
-	err = json.Unmarshal(jsonData, &locations)
-	if err != nil {
-		log.Fatalf("Error unmarshalling JSON: %v", err)
-	}
+// setLocations builds the indexes for a dataset and then swaps it in, so searches
+// keep using the previous dataset until the new one is complete
+func (s *LocationService) setLocations(locations []models.Location) {
+	s.swapLocations(locations, indexLocations(locations))
+}
 
+// swapLocations replaces the dataset and its indexes at once
+func (s *LocationService) swapLocations(locations []models.Location, indexes locationIndexes) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.locations = locations
-	s.initialized = true
-	log.Printf("Loaded %d locations into memory", len(locations))
+	s.byID = indexes.byID
+	s.searchIndex = indexes.searchIndex
+	s.spatialIndex = indexes.spatialIndex
+}
+
+// buildSpatialIndex builds the k-d tree used for nearest-location lookups
+func buildSpatialIndex(locations []models.Location) *kdTree {
+	lats := make([]float64, len(locations))
+	lons := make([]float64, len(locations))
+	for i, location := range locations {
+		lats[i] = location.Lat
+		lons[i] = location.Lon
+	}
+	return newKDTree(lats, lons)
 }
 
 // GetLocations returns all locations (for backward compatibility)
@@ -54,10 +118,6 @@ func (s *LocationService) GetLocations() models.LocationsResponse {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if !s.initialized {
-		s.initializeLocations()
-	}
-
 	return models.LocationsResponse{
 		Locations: s.locations,
 		Total:     len(s.locations),
@@ -69,10 +129,6 @@ func (s *LocationService) SearchLocations(query string, language string, limit i
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if !s.initialized {
-		s.initializeLocations()
-	}
-
 	// Set default limit if not specified
 	if limit <= 0 {
 		limit = 20 // Default to 20 results for good UX
@@ -84,34 +140,27 @@ func (s *LocationService) SearchLocations(query string, language string, limit i
 			limit = len(s.locations)
 		}
 		return models.SearchResponse{
-			Locations: s.locations[:limit],
+			Locations: toSearchResults(s.locations[:limit], 0, ""),
 			Total:     len(s.locations),
 			Query:     "",
 		}
 	}
 
-	// Perform optimized search
+	// Search the precomputed index, preferring matches in the requested language
 	query = strings.ToLower(strings.TrimSpace(query))
-	var results []models.Location
-
-	// First pass: find exact and prefix matches (highest priority)
-	exactMatches := s.findExactMatches(query, language)
-	prefixMatches := s.findPrefixMatches(query, language)
-
-	// Second pass: find contains matches (lower priority)
-	containsMatches := s.findContainsMatches(query, language)
-
-	// Combine results with priority ordering
-	results = append(results, exactMatches...)
-	results = append(results, prefixMatches...)
-	results = append(results, containsMatches...)
-
-	// Remove duplicates while preserving order
-	results = s.removeDuplicates(results)
-
-	// Limit results
-	if len(results) > limit {
-		results = results[:limit]
+	preferredField := fieldEn
+	if language == "bn" {
+		preferredField = fieldBn
+	}
+
+	matches := s.searchIndex.search(query, preferredField, limit)
+	results := make([]models.SearchResult, len(matches))
+	for i, match := range matches {
+		results[i] = models.SearchResult{
+			Location:  s.locations[match.id],
+			Score:     match.score,
+			MatchType: string(match.matchType),
+		}
 	}
 
 	return models.SearchResponse{
@@ -121,97 +170,79 @@ func (s *LocationService) SearchLocations(query string, language string, limit i
 	}
 }
 
-// findExactMatches finds locations that exactly match the query
-func (s *LocationService) findExactMatches(query, language string) []models.Location {
-	var matches []models.Location
-
-	for _, location := range s.locations {
-		if language == "bn" && strings.ToLower(location.NameBn) == query {
-			matches = append(matches, location)
-		} else if language == "en" && strings.ToLower(location.NameEn) == query {
-			matches = append(matches, location)
-		} else if strings.ToLower(location.NameEn) == query || strings.ToLower(location.NameBn) == query {
-			matches = append(matches, location)
-		}
+// toSearchResults wraps locations as search results with the given score
+func toSearchResults(locations []models.Location, score float64, matchType models.MatchType) []models.SearchResult {
+	results := make([]models.SearchResult, len(locations))
+	for i, location := range locations {
+		results[i] = models.SearchResult{Location: location, Score: score, MatchType: string(matchType)}
 	}
-
-	return matches
+	return results
 }
 
-// findPrefixMatches finds locations that start with the query
-func (s *LocationService) findPrefixMatches(query, language string) []models.Location {
-	var matches []models.Location
-
-	for _, location := range s.locations {
-		if language == "bn" && strings.HasPrefix(strings.ToLower(location.NameBn), query) {
-			matches = append(matches, location)
-		} else if language == "en" && strings.HasPrefix(strings.ToLower(location.NameEn), query) {
-			matches = append(matches, location)
-		} else if strings.HasPrefix(strings.ToLower(location.NameEn), query) || strings.HasPrefix(strings.ToLower(location.NameBn), query) {
-			matches = append(matches, location)
-		}
-	}
+// GetTotalLocations returns the total count of locations
+func (s *LocationService) GetTotalLocations() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return matches
+	return len(s.locations)
 }
 
-// findContainsMatches finds locations that contain the query
-func (s *LocationService) findContainsMatches(query, language string) []models.Location {
-	var matches []models.Location
+// GetLocationByID returns a specific location by its stable ID, or nil if it does not exist
+func (s *LocationService) GetLocationByID(id string) *models.Location {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	for _, location := range s.locations {
-		if language == "bn" && strings.Contains(strings.ToLower(location.NameBn), query) {
-			matches = append(matches, location)
-		} else if language == "en" && strings.Contains(strings.ToLower(location.NameEn), query) {
-			matches = append(matches, location)
-		} else if strings.Contains(strings.ToLower(location.NameEn), query) || strings.Contains(strings.ToLower(location.NameBn), query) {
-			matches = append(matches, location)
-		}
+	if index, ok := s.byID[id]; ok {
+		location := s.locations[index]
+		return &location
 	}
 
-	return matches
+	return nil
 }
 
-// removeDuplicates removes duplicate locations while preserving order
-func (s *LocationService) removeDuplicates(locations []models.Location) []models.Location {
-	seen := make(map[string]bool)
-	var unique []models.Location
+// ResolveFareLocations replaces the start and end locations of a fare request with the
+// stored locations when their IDs are given, so quotes always use trusted coordinates
+func (s *LocationService) ResolveFareLocations(request *models.FareRequest) error {
+	return s.ResolveLocations(request.StartLocationID, &request.StartLocation, request.EndLocationID, &request.EndLocation)
+}
 
-	for _, location := range locations {
-		key := location.NameEn + "|" + location.NameBn + "|" + string(rune(int(location.Lat))) + "|" + string(rune(int(location.Lon)))
-		if !seen[key] {
-			seen[key] = true
-			unique = append(unique, location)
+// ResolveLocations replaces each start and end location with the stored location when its ID is given
+func (s *LocationService) ResolveLocations(startID string, start *models.Location, endID string, end *models.Location) error {
+	if startID != "" {
+		location := s.GetLocationByID(startID)
+		if location == nil {
+			return fmt.Errorf("unknown start location %q", startID)
 		}
+		*start = *location
 	}
-
-	return unique
-}
-
-// GetTotalLocations returns the total count of locations
-func (s *LocationService) GetTotalLocations() int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if !s.initialized {
-		s.initializeLocations()
+	if endID != "" {
+		location := s.GetLocationByID(endID)
+		if location == nil {
+			return fmt.Errorf("unknown end location %q", endID)
+		}
+		*end = *location
 	}
-
-	return len(s.locations)
+	return nil
 }
 
-// GetLocationByID returns a specific location by index (useful for selection)
-func (s *LocationService) GetLocationByID(id int) *models.Location {
+// NearestLocations returns the k locations closest to the given coordinates, closest first
+func (s *LocationService) NearestLocations(lat, lon float64, k int) models.NearestResponse {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if !s.initialized {
-		s.initializeLocations()
+	results := make([]models.NearestLocation, 0, k)
+	for _, index := range s.spatialIndex.nearest(lat, lon, k) {
+		location := s.locations[index]
+		results = append(results, models.NearestLocation{
+			Location:       location,
+			DistanceMeters: math.Round(utils.HaversineMeters(lat, lon, location.Lat, location.Lon)),
+		})
 	}
 
-	if id >= 0 && id < len(s.locations) {
-		return &s.locations[id]
+	return models.NearestResponse{
+		Locations: results,
+		Total:     len(results),
+		Lat:       lat,
+		Lon:       lon,
 	}
-
-	return nil
 }
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// bengaliToLatin maps Bengali letters and signs to their common romanization.
+// Consonants are mapped without the inherent vowel since vowels are dropped from phonetic keys.
+var bengaliToLatin = map[rune]string{
+	// Consonants
+	'ক': "k", 'খ': "kh", 'গ': "g", 'ঘ': "gh", 'ঙ': "ng",
+	'চ': "ch", 'ছ': "chh", 'জ': "j", 'ঝ': "jh", 'ঞ': "n",
+	'ট': "t", 'ঠ': "th", 'ড': "d", 'ঢ': "dh", 'ণ': "n",
+	'ত': "t", 'থ': "th", 'দ': "d", 'ধ': "dh", 'ন': "n",
+	'প': "p", 'ফ': "f", 'ব': "b", 'ভ': "bh", 'ম': "m",
+	'য': "j", 'র': "r", 'ল': "l", 'শ': "sh", 'ষ': "sh",
+	'স': "s", 'হ': "h", '\u09DC': "r", '\u09DD': "r", '\u09DF': "y", // ড়, ঢ়, য়
+	'ৎ': "t", 'ং': "ng", 'ঃ': "h", 'ঁ': "",
+	// Independent vowels
+	'অ': "a", 'আ': "a", 'ই': "i", 'ঈ': "i", 'উ': "u", 'ঊ': "u",
+	'ঋ': "ri", 'এ': "e", 'ঐ': "oi", 'ও': "o", 'ঔ': "ou",
+	// Vowel signs
+	'া': "a", 'ি': "i", 'ী': "i", 'ু': "u", 'ূ': "u", 'ৃ': "ri",
+	'ে': "e", 'ৈ': "oi", 'ো': "o", 'ৌ': "ou", 'ৗ': "ou",
+	// Digits
+	'০': "0", '১': "1", '২': "2", '৩': "3", '৪': "4",
+	'৫': "5", '৬': "6", '৭': "7", '৮': "8", '৯': "9",
+}
+
+// Bengali signs that change the letter around them
+const (
+	bengaliHasanta = '্' // Suppresses the inherent vowel, forming a conjunct
+	bengaliNukta   = '়' // Turns ড, ঢ and য into ড়, ঢ় and য়
+)
+
+// transliterateBengali converts Bengali script to a lowercase Latin spelling; Latin text passes through
+func transliterateBengali(text string) string {
+	runes := []rune(text)
+	var builder strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		// Decomposed nukta forms
+		if i+1 < len(runes) && runes[i+1] == bengaliNukta {
+			switch r {
+			case 'ড', 'ঢ':
+				builder.WriteString("r")
+				i++
+				continue
+			case 'য':
+				builder.WriteString("y")
+				i++
+				continue
+			}
+		}
+
+		// য and ব after a hasanta are the ya-phala and ba-phala, which only colour the vowel
+		if i > 0 && runes[i-1] == bengaliHasanta && (r == 'য' || r == 'ব') {
+			builder.WriteString("y")
+			continue
+		}
+
+		if r == bengaliHasanta || r == bengaliNukta {
+			continue
+		}
+
+		if latin, ok := bengaliToLatin[r]; ok {
+			builder.WriteString(latin)
+			continue
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+
+	return builder.String()
+}
+
+// phoneticKey returns a spelling-independent key for a place name written in
+// Bengali or romanized Bengali ("Banglish"). Aspirated consonants are merged with
+// their plain forms, common letter swaps (z/j, v/b, ph/f) are unified, doubled
+// consonants are collapsed and vowels are dropped, so "Mohakhali", "mohakhali"
+// and "মহাখালী" all produce "mhkl".
+func phoneticKey(name string) string {
+	latin := transliterateBengali(strings.ToLower(name))
+
+	var letters []rune
+	for _, r := range latin {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			letters = append(letters, r)
+		}
+	}
+
+	var key []rune
+	for i := 0; i < len(letters); i++ {
+		r := letters[i]
+		next := rune(0)
+		if i+1 < len(letters) {
+			next = letters[i+1]
+		}
+
+		var code rune
+		switch {
+		case next == 'h' && strings.ContainsRune("kgcjtdpbs", r):
+			// Aspirated consonants and "sh" sound like their plain forms
+			code = r
+			if r == 'p' {
+				code = 'f'
+			}
+			i++
+			for i+1 < len(letters) && letters[i+1] == 'h' {
+				i++ // "chh"
+			}
+		case r == 'c':
+			code = 'k' // A lone c is a k sound; "ch" is handled above
+		case r == 'q':
+			code = 'k'
+		case r == 'z':
+			code = 'j'
+		case r == 'v':
+			code = 'b'
+		case r == 'x':
+			key = appendConsonant(key, 'k')
+			code = 's'
+		case strings.ContainsRune("aeiouyw", r):
+			// Vowels and semivowels are dropped, except a leading vowel which is kept as a marker
+			if len(key) == 0 {
+				key = append(key, 'a')
+			}
+			continue
+		default:
+			code = r
+		}
+
+		key = appendConsonant(key, code)
+	}
+
+	return string(key)
+}
+
+// appendConsonant appends a consonant code unless it repeats the previous one
+func appendConsonant(key []rune, code rune) []rune {
+	if len(key) > 0 && key[len(key)-1] == code {
+		return key
+	}
+	return append(key, code)
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+const (
+	maxBusRegistrationLength = 40
+	maxReportNoteLength      = 1000
+)
+
+// ErrReportNotSaved is returned when a report could not be written to the reports file
+var ErrReportNotSaved = errors.New("report could not be saved")
+
+// ReportService stores passenger fare reports in an append-only JSON Lines file
+type ReportService struct {
+	path    string
+	mu      sync.RWMutex
+	reports []models.Report // In submission order
+	torn    bool            // The file ends in a partly written line, so the next report starts a new one
+}
+
+// NewReportService creates a new report service, loading the reports already stored at path.
+// Lines that cannot be parsed, such as one torn by a crash while it was appended, are skipped.
+func NewReportService(path string) (*ReportService, error) {
+	service := &ReportService{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return service, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reports: %v", err)
+	}
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var report models.Report
+		if err := json.Unmarshal(line, &report); err != nil {
+			log.Printf("⚠️ Skipping unreadable report on line %d of %s: %v", i+1, path, err)
+			continue
+		}
+		service.reports = append(service.reports, report)
+	}
+	service.torn = len(data) > 0 && data[len(data)-1] != '\n'
+
+	return service, nil
+}
+
+// ValidateReport checks the report fields that are not part of the fare check, so a
+// report can be refused before its fare is worked out
+func (s *ReportService) ValidateReport(request models.ReportRequest) error {
+	if request.DepartureTime == "" {
+		return fmt.Errorf("invalid request: departureTime is required, the legal fare depends on when the trip was made")
+	}
+	if _, err := departureTime(request.DepartureTime); err != nil {
+		return err
+	}
+	registration := strings.Join(strings.Fields(request.BusRegistration), " ")
+	if registration == "" {
+		return fmt.Errorf("invalid request: busRegistration is required")
+	}
+	if len(registration) > maxBusRegistrationLength {
+		return fmt.Errorf("invalid request: busRegistration is longer than %d characters", maxBusRegistrationLength)
+	}
+	if len([]rune(strings.TrimSpace(request.Note))) > maxReportNoteLength {
+		return fmt.Errorf("invalid request: note is longer than %d characters", maxReportNoteLength)
+	}
+	return nil
+}
+
+// SubmitReport stores a report of a trip together with the result of checking its fare
+func (s *ReportService) SubmitReport(request models.ReportRequest, check *models.FareCheckResponse) (*models.Report, error) {
+	if err := s.ValidateReport(request); err != nil {
+		return nil, err
+	}
+	registration := strings.Join(strings.Fields(request.BusRegistration), " ")
+	note := strings.TrimSpace(request.Note)
+
+	id, err := newReportID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(dhakaTimezone)
+	tripTime, _ := departureTime(request.DepartureTime)
+
+	report := models.Report{
+		ID:               id,
+		RouteID:          request.RouteID,
+		OperatorID:       request.OperatorID,
+		BusType:          check.LegalFare.BusType,
+		BusRegistration:  strings.ToUpper(registration),
+		BoardingStop:     request.BoardingStop,
+		AlightingStop:    request.AlightingStop,
+		TripTime:         tripTime.In(dhakaTimezone).Format(time.RFC3339),
+		AmountCharged:    check.AmountCharged,
+		LegalFare:        check.LegalFare.Fare,
+		Overcharged:      check.Overcharged,
+		OverchargeAmount: check.OverchargeAmount,
+		FareTableVersion: check.LegalFare.FareTableVersion,
+		Note:             note,
+		SubmittedAt:      now.Format(time.RFC3339),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.appendReport(report); err != nil {
+		log.Printf("Error saving report: %v", err)
+		return nil, ErrReportNotSaved
+	}
+	s.reports = append(s.reports, report)
+	return &report, nil
+}
+
+// appendReport writes a report as one line at the end of the reports file
+func (s *ReportService) appendReport(report models.Report) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %v", err)
+	}
+	line = append(line, '\n')
+	if s.torn {
+		line = append([]byte("\n"), line...)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory: %v", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open reports: %v", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		s.torn = true
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	s.torn = false
+	return nil
+}
+
+// GetReports returns the reports matching a filter, newest first
+func (s *ReportService) GetReports(filter models.ReportFilter) models.ReportsResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := []models.Report{}
+	for i := len(s.reports) - 1; i >= 0; i-- {
+		if reportMatches(s.reports[i], filter) {
+			reports = append(reports, s.reports[i])
+		}
+	}
+
+	return models.ReportsResponse{
+		Reports: reports,
+		Total:   len(reports),
+	}
+}
+
+// GetReportStats counts the reports matching a filter, overall and by route and operator
+func (s *ReportService) GetReportStats(filter models.ReportFilter) models.ReportStatsResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total utils.Poisha
+	response := models.ReportStatsResponse{}
+	byRoute := make(map[string]*reportTally)
+	byOperator := make(map[string]*reportTally)
+	for _, report := range s.reports {
+		if !reportMatches(report, filter) {
+			continue
+		}
+		overcharge := utils.TakaToPoisha(report.OverchargeAmount)
+		response.Reports++
+		if report.Overcharged {
+			response.Overcharged++
+		}
+		total += overcharge
+		tally(byRoute, report.RouteID, report.Overcharged, overcharge)
+		tally(byOperator, report.OperatorID, report.Overcharged, overcharge)
+	}
+
+	response.TotalOvercharge = total.Taka()
+	response.ByRoute = reportCounts(byRoute)
+	response.ByOperator = reportCounts(byOperator)
+	return response
+}
+
+// reportTally accumulates the reports of one route or operator
+type reportTally struct {
+	reports     int
+	overcharged int
+	overcharge  utils.Poisha
+}
+
+// tally adds a report to the tally for id
+func tally(tallies map[string]*reportTally, id string, overcharged bool, overcharge utils.Poisha) {
+	t, ok := tallies[id]
+	if !ok {
+		t = &reportTally{}
+		tallies[id] = t
+	}
+	t.reports++
+	if overcharged {
+		t.overcharged++
+	}
+	t.overcharge += overcharge
+}
+
+// reportCounts lists tallies with the most reported first
+func reportCounts(tallies map[string]*reportTally) []models.ReportCount {
+	counts := make([]models.ReportCount, 0, len(tallies))
+	for id, t := range tallies {
+		counts = append(counts, models.ReportCount{
+			ID:              id,
+			Reports:         t.reports,
+			Overcharged:     t.overcharged,
+			TotalOvercharge: t.overcharge.Taka(),
+		})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Reports != counts[j].Reports {
+			return counts[i].Reports > counts[j].Reports
+		}
+		return counts[i].ID < counts[j].ID
+	})
+	return counts
+}
+
+// reportMatches checks a report against a filter
+func reportMatches(report models.Report, filter models.ReportFilter) bool {
+	return (filter.RouteID == "" || report.RouteID == filter.RouteID) &&
+		(filter.OperatorID == "" || report.OperatorID == filter.OperatorID)
+}
+
+// newReportID returns a random report ID
+func newReportID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate report id: %v", err)
+	}
+	return "r-" + hex.EncodeToString(b), nil
+}
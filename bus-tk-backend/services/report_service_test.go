@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// testReport returns a report request for a trip on a route
+func testReport(routeID, operatorID string) models.ReportRequest {
+	return models.ReportRequest{
+		FareCheckRequest: models.FareCheckRequest{
+			FareRequest:   models.FareRequest{RouteID: routeID, OperatorID: operatorID, DepartureTime: testDeparture},
+			AmountCharged: 70,
+		},
+		BusRegistration: "Dhaka Metro-Ba 11-2345",
+	}
+}
+
+// testFareCheck returns the result of checking a charge against a legal fare of Tk 50
+func testFareCheck(charged float64) *models.FareCheckResponse {
+	overcharge := max(0, charged-50)
+	return &models.FareCheckResponse{
+		LegalFare:        models.FareResponse{Fare: 50, BusType: "nonAC", FareTableVersion: "2024-01"},
+		AmountCharged:    charged,
+		Overcharged:      overcharge > 0,
+		OverchargeAmount: overcharge,
+	}
+}
+
+// newTestReportService creates a report service storing reports in a temporary file
+func newTestReportService(t *testing.T) (*ReportService, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "reports.jsonl")
+	service, err := NewReportService(path)
+	if err != nil {
+		t.Fatalf("NewReportService: %v", err)
+	}
+	return service, path
+}
+
+func TestReportsRoundTrip(t *testing.T) {
+	service, path := newTestReportService(t)
+
+	request := testReport("bikash-1", "bikash")
+	request.BusRegistration = "  dhaka metro-ba   11-2345 "
+	request.Note = " Conductor refused change "
+	first, err := service.SubmitReport(request, testFareCheck(70))
+	if err != nil {
+		t.Fatalf("SubmitReport: %v", err)
+	}
+	if first.BusRegistration != "DHAKA METRO-BA 11-2345" || first.Note != "Conductor refused change" {
+		t.Errorf("registration %q, note %q, want them normalized", first.BusRegistration, first.Note)
+	}
+	if first.TripTime != testDeparture || !first.Overcharged || first.OverchargeAmount != 20 || first.LegalFare != 50 {
+		t.Errorf("report = %+v, want the trip time and fare check", first)
+	}
+	second, err := service.SubmitReport(testReport("shikhor-1", "shikhor"), testFareCheck(50))
+	if err != nil {
+		t.Fatalf("SubmitReport: %v", err)
+	}
+
+	reloaded, err := NewReportService(path)
+	if err != nil {
+		t.Fatalf("NewReportService: %v", err)
+	}
+	reports := reloaded.GetReports(models.ReportFilter{}).Reports
+	if len(reports) != 2 || reports[0] != *second || reports[1] != *first {
+		t.Errorf("reloaded %+v, want the second and first report", reports)
+	}
+}
+
+func TestReportsReloadSkipsBadLines(t *testing.T) {
+	service, path := newTestReportService(t)
+	kept, err := service.SubmitReport(testReport("bikash-1", "bikash"), testFareCheck(70))
+	if err != nil {
+		t.Fatalf("SubmitReport: %v", err)
+	}
+
+	// An invalid line, a blank line and a line torn by a crash mid-append
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if _, err := file.WriteString("not a report\n\n{\"id\": \"r-torn\", \"routeId\": \"bik"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	file.Close()
+
+	reloaded, err := NewReportService(path)
+	if err != nil {
+		t.Fatalf("NewReportService: %v", err)
+	}
+	if reports := reloaded.GetReports(models.ReportFilter{}).Reports; len(reports) != 1 || reports[0] != *kept {
+		t.Fatalf("reloaded %+v, want only the valid report", reports)
+	}
+
+	// A report added after the torn line starts a line of its own and survives another reload
+	added, err := reloaded.SubmitReport(testReport("shikhor-1", "shikhor"), testFareCheck(60))
+	if err != nil {
+		t.Fatalf("SubmitReport: %v", err)
+	}
+	again, err := NewReportService(path)
+	if err != nil {
+		t.Fatalf("NewReportService: %v", err)
+	}
+	if reports := again.GetReports(models.ReportFilter{}).Reports; len(reports) != 2 || reports[0] != *added || reports[1] != *kept {
+		t.Errorf("reloaded %+v, want the added and kept reports", reports)
+	}
+}
+
+func TestReportFiltersAndStats(t *testing.T) {
+	service, _ := newTestReportService(t)
+	for _, report := range []struct {
+		routeID, operatorID string
+		charged             float64
+	}{
+		{"bikash-1", "bikash", 70},
+		{"bikash-1", "bikash", 50},
+		{"bikash-2", "bikash", 55.5},
+		{"shikhor-1", "shikhor", 60},
+	} {
+		if _, err := service.SubmitReport(testReport(report.routeID, report.operatorID), testFareCheck(report.charged)); err != nil {
+			t.Fatalf("SubmitReport: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		filter          models.ReportFilter
+		wantReports     int
+		wantOvercharged int
+		wantOvercharge  float64
+		wantTopRoute    models.ReportCount
+		wantOperators   int
+	}{
+		{
+			name: "all", wantReports: 4, wantOvercharged: 3, wantOvercharge: 35.5,
+			wantTopRoute: models.ReportCount{ID: "bikash-1", Reports: 2, Overcharged: 1, TotalOvercharge: 20}, wantOperators: 2,
+		},
+		{
+			name: "by route", filter: models.ReportFilter{RouteID: "bikash-2"}, wantReports: 1, wantOvercharged: 1, wantOvercharge: 5.5,
+			wantTopRoute: models.ReportCount{ID: "bikash-2", Reports: 1, Overcharged: 1, TotalOvercharge: 5.5}, wantOperators: 1,
+		},
+		{
+			name: "by operator", filter: models.ReportFilter{OperatorID: "bikash"}, wantReports: 3, wantOvercharged: 2, wantOvercharge: 25.5,
+			wantTopRoute: models.ReportCount{ID: "bikash-1", Reports: 2, Overcharged: 1, TotalOvercharge: 20}, wantOperators: 1,
+		},
+		{
+			name: "route and operator disagree", filter: models.ReportFilter{RouteID: "bikash-1", OperatorID: "shikhor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := service.GetReports(tt.filter)
+			if reports.Total != tt.wantReports || len(reports.Reports) != tt.wantReports {
+				t.Errorf("listed %d reports (total %d), want %d", len(reports.Reports), reports.Total, tt.wantReports)
+			}
+			for _, report := range reports.Reports {
+				if !reportMatches(report, tt.filter) {
+					t.Errorf("listed %s on %s by %s", report.ID, report.RouteID, report.OperatorID)
+				}
+			}
+
+			stats := service.GetReportStats(tt.filter)
+			if stats.Reports != tt.wantReports || stats.Overcharged != tt.wantOvercharged || stats.TotalOvercharge != tt.wantOvercharge {
+				t.Errorf("stats = %d reports, %d overcharged by %v; want %d, %d by %v",
+					stats.Reports, stats.Overcharged, stats.TotalOvercharge, tt.wantReports, tt.wantOvercharged, tt.wantOvercharge)
+			}
+			if len(stats.ByOperator) != tt.wantOperators {
+				t.Errorf("by operator = %+v, want %d operators", stats.ByOperator, tt.wantOperators)
+			}
+			if tt.wantReports > 0 && (len(stats.ByRoute) == 0 || stats.ByRoute[0] != tt.wantTopRoute) {
+				t.Errorf("by route = %+v, want %+v first", stats.ByRoute, tt.wantTopRoute)
+			}
+		})
+	}
+}
+
+func TestSubmitReportRejectsInvalidReports(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(request *models.ReportRequest)
+	}{
+		{"no departure time", func(request *models.ReportRequest) { request.DepartureTime = "" }},
+		{"bad departure time", func(request *models.ReportRequest) { request.DepartureTime = "last night" }},
+		{"no registration", func(request *models.ReportRequest) { request.BusRegistration = "   " }},
+		{"registration too long", func(request *models.ReportRequest) {
+			request.BusRegistration = "Dhaka Metro-Ba 11-2345 Dhaka Metro-Ba 11-2345"
+		}},
+		{"note too long", func(request *models.ReportRequest) { request.Note = string(make([]rune, 1001)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, path := newTestReportService(t)
+			request := testReport("bikash-1", "bikash")
+			tt.modify(&request)
+			if _, err := service.SubmitReport(request, testFareCheck(70)); err == nil || errors.Is(err, ErrReportNotSaved) {
+				t.Errorf("err = %v, want the report rejected", err)
+			}
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("rejected report was written: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubmitReportNotSaved(t *testing.T) {
+	service, path := newTestReportService(t)
+	// A directory in place of the reports file cannot be appended to
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+
+	if _, err := service.SubmitReport(testReport("bikash-1", "bikash"), testFareCheck(70)); !errors.Is(err, ErrReportNotSaved) {
+		t.Errorf("err = %v, want ErrReportNotSaved", err)
+	}
+	if total := service.GetReports(models.ReportFilter{}).Total; total != 0 {
+		t.Errorf("%d unsaved reports listed, want none", total)
+	}
+}
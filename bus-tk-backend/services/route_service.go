@@ -0,0 +1,280 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// RouteService handles bus route, stop and operator lookups
+type RouteService struct {
+	operators    map[string]models.Operator
+	stops        map[string]models.Stop
+	routes       map[string]models.Route
+	routeIDs     []string            // Route IDs in file order
+	routesByStop map[string][]string // Stop ID to the IDs of routes serving it
+}
+
+// NewRouteService creates a new route service from a route network
+func NewRouteService(network models.RouteNetwork) (*RouteService, error) {
+	service := &RouteService{
+		operators:    make(map[string]models.Operator),
+		stops:        make(map[string]models.Stop),
+		routes:       make(map[string]models.Route),
+		routesByStop: make(map[string][]string),
+	}
+
+	for _, operator := range network.Operators {
+		if operator.ID == "" {
+			return nil, fmt.Errorf("operator without id")
+		}
+		if _, exists := service.operators[operator.ID]; exists {
+			return nil, fmt.Errorf("duplicate operator id %q", operator.ID)
+		}
+		service.operators[operator.ID] = operator
+	}
+
+	for _, stop := range network.Stops {
+		if stop.ID == "" {
+			return nil, fmt.Errorf("stop without id")
+		}
+		if _, exists := service.stops[stop.ID]; exists {
+			return nil, fmt.Errorf("duplicate stop id %q", stop.ID)
+		}
+		if !validCoordinates(stop.Lat, stop.Lon) {
+			return nil, fmt.Errorf("stop %q: invalid coordinates %v, %v", stop.ID, stop.Lat, stop.Lon)
+		}
+		service.stops[stop.ID] = stop
+	}
+
+	for _, route := range network.Routes {
+		if err := service.validateRoute(route); err != nil {
+			return nil, fmt.Errorf("route %q: %v", route.ID, err)
+		}
+		service.routes[route.ID] = route
+		service.routeIDs = append(service.routeIDs, route.ID)
+
+		seen := make(map[string]bool)
+		for _, stopID := range route.StopIDs {
+			if !seen[stopID] {
+				seen[stopID] = true
+				service.routesByStop[stopID] = append(service.routesByStop[stopID], route.ID)
+			}
+		}
+	}
+
+	return service, nil
+}
+
+// LoadRouteNetwork reads the route network from a JSON file
+func LoadRouteNetwork(path string) (models.RouteNetwork, error) {
+	var network models.RouteNetwork
+
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return network, fmt.Errorf("failed to read route network: %v", err)
+	}
+
+	if err := json.Unmarshal(jsonData, &network); err != nil {
+		return network, fmt.Errorf("failed to parse route network: %v", err)
+	}
+
+	return network, nil
+}
+
+// validateRoute checks that a route has a known bus type and refers to known operators and stops
+func (s *RouteService) validateRoute(route models.Route) error {
+	if route.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if _, exists := s.routes[route.ID]; exists {
+		return fmt.Errorf("duplicate route id")
+	}
+	if _, ok := s.operators[route.OperatorID]; !ok {
+		return fmt.Errorf("unknown operator %q", route.OperatorID)
+	}
+	if route.BusType != string(models.BusTypeNonAC) && route.BusType != string(models.BusTypeAC) {
+		return fmt.Errorf("bus type must be %q or %q, got %q", models.BusTypeNonAC, models.BusTypeAC, route.BusType)
+	}
+	if len(route.StopIDs) < 2 {
+		return fmt.Errorf("at least two stops are required")
+	}
+	for _, stopID := range route.StopIDs {
+		if _, ok := s.stops[stopID]; !ok {
+			return fmt.Errorf("unknown stop %q", stopID)
+		}
+	}
+	return nil
+}
+
+// ValidateFareChart checks that a fare chart only prices routes of the network, between
+// stops those routes serve, so its fares cannot end up applied to other routes
+func (s *RouteService) ValidateFareChart(chart *models.FareChart) error {
+	for _, chartRoute := range chart.Routes {
+		route, ok := s.routes[chartRoute.RouteID]
+		if !ok {
+			return fmt.Errorf("fare chart route %q is not in the route network", chartRoute.RouteID)
+		}
+		for _, entry := range chartRoute.Fares {
+			if !slices.Contains(route.StopIDs, entry.From) || !slices.Contains(route.StopIDs, entry.To) {
+				return fmt.Errorf("fare chart entry %s-%s on route %q is not between stops of the route", entry.From, entry.To, route.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// GetRoutes returns all routes, optionally only those of one operator
+func (s *RouteService) GetRoutes(operatorID string) models.RoutesResponse {
+	routes := make([]models.RouteSummary, 0, len(s.routeIDs))
+	for _, routeID := range s.routeIDs {
+		route := s.routes[routeID]
+		if operatorID != "" && route.OperatorID != operatorID {
+			continue
+		}
+		routes = append(routes, s.summarize(route))
+	}
+
+	return models.RoutesResponse{
+		Routes: routes,
+		Total:  len(routes),
+	}
+}
+
+// GetRoute returns a route with its operator and ordered stops, or nil if it does not exist
+func (s *RouteService) GetRoute(id string) *models.RouteDetail {
+	route, ok := s.routes[id]
+	if !ok {
+		return nil
+	}
+
+	stops := make([]models.Stop, len(route.StopIDs))
+	for i, stopID := range route.StopIDs {
+		stops[i] = s.stops[stopID]
+	}
+
+	return &models.RouteDetail{
+		RouteSummary: s.summarize(route),
+		Stops:        stops,
+	}
+}
+
+// GetStop returns a stop, or nil if it does not exist
+func (s *RouteService) GetStop(id string) *models.Stop {
+	stop, ok := s.stops[id]
+	if !ok {
+		return nil
+	}
+	return &stop
+}
+
+// GetRoutesForStop returns the routes serving a stop, or nil if the stop does not exist
+func (s *RouteService) GetRoutesForStop(stopID string) *models.RoutesResponse {
+	if _, ok := s.stops[stopID]; !ok {
+		return nil
+	}
+
+	routeIDs := append([]string(nil), s.routesByStop[stopID]...)
+	sort.Strings(routeIDs)
+
+	routes := make([]models.RouteSummary, 0, len(routeIDs))
+	for _, routeID := range routeIDs {
+		routes = append(routes, s.summarize(s.routes[routeID]))
+	}
+
+	return &models.RoutesResponse{
+		Routes: routes,
+		Total:  len(routes),
+	}
+}
+
+// GetOperator returns an operator, or nil if it does not exist
+func (s *RouteService) GetOperator(id string) *models.Operator {
+	operator, ok := s.operators[id]
+	if !ok {
+		return nil
+	}
+	return &operator
+}
+
+// RoutesBetween returns the routes serving both stops, in file order
+func (s *RouteService) RoutesBetween(fromStopID, toStopID string) []models.RouteSummary {
+	serves := make(map[string]bool)
+	for _, routeID := range s.routesByStop[toStopID] {
+		serves[routeID] = true
+	}
+
+	routes := []models.RouteSummary{}
+	for _, routeID := range s.routeIDs {
+		if fromStopID != toStopID && serves[routeID] && slices.Contains(s.routesByStop[fromStopID], routeID) {
+			routes = append(routes, s.summarize(s.routes[routeID]))
+		}
+	}
+	return routes
+}
+
+// ResolveFareOperator fills in a fare request's operator from its route and
+// rejects operators that do not exist or do not run the route
+func (s *RouteService) ResolveFareOperator(request *models.FareRequest) error {
+	if request.OperatorID != "" && s.GetOperator(request.OperatorID) == nil {
+		return fmt.Errorf("operator %q not found", request.OperatorID)
+	}
+
+	route, ok := s.routes[request.RouteID]
+	if !ok {
+		return nil
+	}
+	if request.OperatorID == "" {
+		request.OperatorID = route.OperatorID
+	} else if request.OperatorID != route.OperatorID {
+		return fmt.Errorf("route %q is not run by operator %q", route.ID, request.OperatorID)
+	}
+	return nil
+}
+
+// Network returns the loaded route network, with routes in file order and
+// operators and stops sorted by ID
+func (s *RouteService) Network() models.RouteNetwork {
+	operators := make([]models.Operator, 0, len(s.operators))
+	for _, operator := range s.operators {
+		operators = append(operators, operator)
+	}
+	sort.Slice(operators, func(i, j int) bool {
+		return operators[i].ID < operators[j].ID
+	})
+
+	routes := make([]models.Route, len(s.routeIDs))
+	for i, routeID := range s.routeIDs {
+		routes[i] = s.routes[routeID]
+	}
+
+	return models.RouteNetwork{
+		Operators: operators,
+		Stops:     s.allStops(),
+		Routes:    routes,
+	}
+}
+
+// allStops returns every stop sorted by ID
+func (s *RouteService) allStops() []models.Stop {
+	stops := make([]models.Stop, 0, len(s.stops))
+	for _, stop := range s.stops {
+		stops = append(stops, stop)
+	}
+	sort.Slice(stops, func(i, j int) bool {
+		return stops[i].ID < stops[j].ID
+	})
+	return stops
+}
+
+// summarize attaches the operator to a route
+func (s *RouteService) summarize(route models.Route) models.RouteSummary {
+	return models.RouteSummary{
+		Route:    route,
+		Operator: s.operators[route.OperatorID],
+	}
+}
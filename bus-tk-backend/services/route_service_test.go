@@ -0,0 +1,67 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+func TestValidateFareChartAgainstNetwork(t *testing.T) {
+	routeService, err := NewRouteService(testTripNetwork())
+	if err != nil {
+		t.Fatalf("NewRouteService: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		routes  []models.FareChartRoute
+		wantErr bool
+	}{
+		{name: "empty chart"},
+		{name: "stops of the route", routes: []models.FareChartRoute{{RouteID: "r1", Fares: []models.FareChartEntry{{From: "a", To: "c", Fare: 20}}}}},
+		{name: "unknown route", routes: []models.FareChartRoute{{RouteID: "r9", Fares: []models.FareChartEntry{{From: "a", To: "c", Fare: 20}}}}, wantErr: true},
+		{name: "stop of another route", routes: []models.FareChartRoute{{RouteID: "r1", Fares: []models.FareChartEntry{{From: "a", To: "d", Fare: 20}}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := routeService.ValidateFareChart(&models.FareChart{Version: "test", Routes: tt.routes})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRouteServiceRejectsInvalidNetworks(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(network *models.RouteNetwork)
+	}{
+		{"missing bus type", func(network *models.RouteNetwork) { network.Routes[0].BusType = "" }},
+		{"unknown bus type", func(network *models.RouteNetwork) { network.Routes[0].BusType = "sleeper" }},
+		{"bus type in the wrong case", func(network *models.RouteNetwork) { network.Routes[1].BusType = "ac" }},
+		{"latitude out of range", func(network *models.RouteNetwork) { network.Stops[0].Lat = 123.75 }},
+		{"longitude out of range", func(network *models.RouteNetwork) { network.Stops[2].Lon = -190.4 }},
+		{"coordinates not a number", func(network *models.RouteNetwork) { network.Stops[3].Lat = math.NaN() }},
+		{"unknown operator", func(network *models.RouteNetwork) { network.Routes[0].OperatorID = "other" }},
+		{"unknown stop", func(network *models.RouteNetwork) { network.Routes[1].StopIDs[2] = "z" }},
+		{"duplicate stop", func(network *models.RouteNetwork) { network.Stops[1].ID = "a" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := testTripNetwork()
+			tt.modify(&network)
+			if _, err := NewRouteService(network); err == nil {
+				t.Error("NewRouteService accepted the network")
+			}
+		})
+	}
+
+	ac := models.Route{ID: "r3", OperatorID: "op", BusType: "AC", StopIDs: []string{"a", "e"}}
+	if _, err := NewRouteService(testTripNetwork(ac)); err != nil {
+		t.Errorf("NewRouteService rejected an AC route: %v", err)
+	}
+}
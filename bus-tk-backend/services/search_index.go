@@ -0,0 +1,312 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// Name fields covered by the search index
+const (
+	fieldEn = iota
+	fieldBn
+	fieldCount
+)
+
+// maxGramLength is the longest substring stored in the n-gram index
+const maxGramLength = 3
+
+// searchIndex is a precomputed index over location names, built once when the
+// locations are loaded so searches never scan or lowercase every name.
+// Location IDs in the index are positions in the location slice.
+type searchIndex struct {
+	names         [fieldCount][]string           // Lowercased names by location
+	exact         [fieldCount]map[string][]int32 // Lowercased name to locations
+	prefixes      [fieldCount]*trieNode          // Prefix trie over lowercased names
+	ngrams        [fieldCount]map[string][]int32 // 1- to 3-rune substrings to locations
+	phoneticExact map[string][]int32             // Phonetic key of either name to locations
+	phonetic      *trieNode                      // Prefix trie over phonetic keys
+	fuzzyGrams    map[string][]int32             // Trigrams of normalized names to locations
+}
+
+// trieNode is a node of a prefix trie
+type trieNode struct {
+	children map[rune]*trieNode
+	ids      []int32 // Locations with a name starting with this prefix, ascending
+}
+
+// indexMatch is a location found by the index
+type indexMatch struct {
+	id        int32
+	score     float64
+	matchType models.MatchType
+}
+
+// newSearchIndex builds the search index for the given locations
+func newSearchIndex(locations []models.Location) *searchIndex {
+	index := &searchIndex{
+		phoneticExact: make(map[string][]int32),
+		phonetic:      newTrieNode(),
+		fuzzyGrams:    make(map[string][]int32),
+	}
+	for field := 0; field < fieldCount; field++ {
+		index.names[field] = make([]string, len(locations))
+		index.exact[field] = make(map[string][]int32)
+		index.prefixes[field] = newTrieNode()
+		index.ngrams[field] = make(map[string][]int32)
+	}
+
+	for i, location := range locations {
+		id := int32(i)
+		for field, name := range [fieldCount]string{location.NameEn, location.NameBn} {
+			lowered := strings.ToLower(strings.TrimSpace(name))
+			index.names[field][i] = lowered
+			if lowered == "" {
+				continue
+			}
+
+			index.exact[field][lowered] = appendID(index.exact[field][lowered], id)
+			index.prefixes[field].insert(lowered, id)
+			for _, gram := range ngrams([]rune(lowered), 1, maxGramLength) {
+				index.ngrams[field][gram] = appendID(index.ngrams[field][gram], id)
+			}
+
+			if key := phoneticKey(name); key != "" {
+				index.phoneticExact[key] = appendID(index.phoneticExact[key], id)
+				index.phonetic.insert(key, id)
+			}
+			for _, gram := range ngrams([]rune(normalizeName(name)), 3, 3) {
+				index.fuzzyGrams[gram] = appendID(index.fuzzyGrams[gram], id)
+			}
+		}
+	}
+
+	return index
+}
+
+// search finds up to limit locations matching the lowercased query, best tiers first.
+// Within a tier, matches on the preferred field come first.
+func (idx *searchIndex) search(query string, preferredField int, limit int) []indexMatch {
+	collector := &matchCollector{seen: make(map[int32]bool), limit: limit}
+	fields := [fieldCount]int{preferredField, 1 - preferredField}
+
+	// Exact, prefix and contains tiers
+	for _, field := range fields {
+		collector.add(idx.exact[field][query], 1.0, models.MatchTypeExact)
+	}
+	for _, field := range fields {
+		collector.add(idx.prefixes[field].find(query), 0.9, models.MatchTypePrefix)
+	}
+	for _, field := range fields {
+		if collector.full() {
+			break
+		}
+		collector.add(idx.contains(field, query), 0.8, models.MatchTypeContains)
+	}
+
+	// Phonetic tier, independent of the script the query is typed in
+	if queryKey := phoneticKey(query); len([]rune(queryKey)) >= 2 {
+		collector.add(idx.phoneticExact[queryKey], 0.75, models.MatchTypePhonetic)
+		collector.add(idx.phonetic.find(queryKey), 0.72, models.MatchTypePhonetic)
+	}
+
+	// Fuzzy tier fills the remaining slots
+	if !collector.full() {
+		for _, match := range idx.fuzzy(query) {
+			collector.addMatch(match)
+		}
+	}
+
+	return collector.matches
+}
+
+// contains returns the locations whose name contains the query, ascending
+func (idx *searchIndex) contains(field int, query string) []int32 {
+	runes := []rune(query)
+	if len(runes) == 0 {
+		return nil
+	}
+
+	// Short queries are themselves indexed n-grams
+	if len(runes) <= maxGramLength {
+		return idx.ngrams[field][query]
+	}
+
+	// Intersect the posting lists of the query's trigrams, smallest first
+	grams := ngrams(runes, maxGramLength, maxGramLength)
+	postings := make([][]int32, 0, len(grams))
+	for _, gram := range grams {
+		posting, ok := idx.ngrams[field][gram]
+		if !ok {
+			return nil
+		}
+		postings = append(postings, posting)
+	}
+	sort.Slice(postings, func(i, j int) bool {
+		return len(postings[i]) < len(postings[j])
+	})
+
+	candidates := postings[0]
+	for _, posting := range postings[1:] {
+		candidates = intersectIDs(candidates, posting)
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	// Trigrams can occur out of order, so confirm the full substring
+	var ids []int32
+	for _, id := range candidates {
+		if strings.Contains(idx.names[field][id], query) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// fuzzy returns locations whose names are close to the query despite typos,
+// best matches first. Candidates must share trigrams with the query.
+func (idx *searchIndex) fuzzy(query string) []indexMatch {
+	grams := ngrams([]rune(normalizeName(query)), 3, 3)
+	if len(grams) == 0 {
+		return nil
+	}
+
+	shared := make(map[int32]int)
+	for _, gram := range grams {
+		for _, id := range idx.fuzzyGrams[gram] {
+			shared[id]++
+		}
+	}
+
+	minShared := max(1, len(grams)/3)
+	var matches []indexMatch
+	for id, count := range shared {
+		if count < minShared {
+			continue
+		}
+
+		score := max(fuzzySimilarity(query, idx.names[fieldEn][id]), fuzzySimilarity(query, idx.names[fieldBn][id]))
+		if score >= fuzzyMinSimilarity {
+			matches = append(matches, indexMatch{
+				id:        id,
+				score:     math.Round(score*fuzzyMaxScore*100) / 100,
+				matchType: models.MatchTypeFuzzy,
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].id < matches[j].id
+	})
+	return matches
+}
+
+// matchCollector gathers unique matches in tier order until the limit is reached
+type matchCollector struct {
+	seen    map[int32]bool
+	matches []indexMatch
+	limit   int
+}
+
+// full reports whether the limit has been reached
+func (c *matchCollector) full() bool {
+	return len(c.matches) >= c.limit
+}
+
+// add collects the given locations with the same score
+func (c *matchCollector) add(ids []int32, score float64, matchType models.MatchType) {
+	for _, id := range ids {
+		if c.full() {
+			return
+		}
+		c.addMatch(indexMatch{id: id, score: score, matchType: matchType})
+	}
+}
+
+// addMatch collects a single match unless its location was already found
+func (c *matchCollector) addMatch(match indexMatch) {
+	if c.full() || c.seen[match.id] {
+		return
+	}
+	c.seen[match.id] = true
+	c.matches = append(c.matches, match)
+}
+
+// newTrieNode creates an empty trie node
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode)}
+}
+
+// insert adds a location under every prefix of the key
+func (n *trieNode) insert(key string, id int32) {
+	node := n
+	for _, r := range key {
+		child, ok := node.children[r]
+		if !ok {
+			child = newTrieNode()
+			node.children[r] = child
+		}
+		child.ids = appendID(child.ids, id)
+		node = child
+	}
+}
+
+// find returns the locations with a key starting with the prefix
+func (n *trieNode) find(prefix string) []int32 {
+	node := n
+	for _, r := range prefix {
+		node = node.children[r]
+		if node == nil {
+			return nil
+		}
+	}
+	return node.ids
+}
+
+// ngrams returns the distinct substrings of the given lengths
+func ngrams(runes []rune, minLength, maxLength int) []string {
+	seen := make(map[string]bool)
+	var grams []string
+	for length := minLength; length <= maxLength; length++ {
+		for start := 0; start+length <= len(runes); start++ {
+			gram := string(runes[start : start+length])
+			if !seen[gram] {
+				seen[gram] = true
+				grams = append(grams, gram)
+			}
+		}
+	}
+	return grams
+}
+
+// appendID appends an ID to an ascending posting list, skipping repeats
+func appendID(ids []int32, id int32) []int32 {
+	if len(ids) > 0 && ids[len(ids)-1] == id {
+		return ids
+	}
+	return append(ids, id)
+}
+
+// intersectIDs returns the IDs present in both ascending lists
+func intersectIDs(a, b []int32) []int32 {
+	var result []int32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
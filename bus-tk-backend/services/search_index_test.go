@@ -0,0 +1,209 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// benchmarkQueries mixes exact, prefix, substring, Bengali and misspelled searches
+var benchmarkQueries = []string{"mirpur", "motijheel", "uttara sector", "ban", "road", "গুলশান", "motijeel", "xyzzy"}
+
+// benchmarkAreas are the names the synthetic benchmark dataset is built from
+var benchmarkAreas = []struct{ en, bn string }{
+	{"Mirpur", "মিরপুর"}, {"Motijheel", "মতিঝিল"}, {"Mohakhali", "মহাখালী"}, {"Gabtali", "গাবতলী"},
+	{"Uttara Sector", "উত্তরা সেক্টর"}, {"Banani", "বনানী"}, {"Gulshan", "গুলশান"}, {"Dhanmondi", "ধানমন্ডি"},
+	{"Farmgate", "ফার্মগেট"}, {"Jatrabari", "যাত্রাবাড়ী"}, {"Badda", "বাড্ডা"}, {"Shyamoli", "শ্যামলী"},
+	{"Kawran Bazar", "কাওরান বাজার"}, {"Malibagh", "মালিবাগ"}, {"Rampura", "রামপুরা"}, {"Tejgaon", "তেজগাঁও"},
+}
+
+// benchmarkSuffixes turn each area into many distinct places, like the real dataset
+var benchmarkSuffixes = []struct{ en, bn string }{
+	{"", ""}, {"Road", "রোড"}, {"Bus Stand", "বাস স্ট্যান্ড"}, {"Bazar", "বাজার"}, {"Mor", "মোড়"},
+	{"School", "স্কুল"}, {"Mosque", "মসজিদ"}, {"Hospital", "হাসপাতাল"}, {"Market", "মার্কেট"},
+}
+
+// benchmarkBaseLocations returns the real dataset when it is present, otherwise a
+// synthetic dataset of about the same size
+func benchmarkBaseLocations(b *testing.B) []models.Location {
+	b.Helper()
+	if data, err := os.ReadFile("../data/dhaka_areas.json"); err == nil {
+		var locations []models.Location
+		if err := json.Unmarshal(data, &locations); err != nil {
+			b.Fatalf("parse dataset: %v", err)
+		}
+		return locations
+	}
+
+	var locations []models.Location
+	for block := 1; len(locations) < 2880; block++ {
+		for _, area := range benchmarkAreas {
+			for _, suffix := range benchmarkSuffixes {
+				locations = append(locations, models.Location{
+					NameEn: strings.TrimSpace(fmt.Sprintf("%s %d %s", area.en, block, suffix.en)),
+					NameBn: strings.TrimSpace(fmt.Sprintf("%s %s %s", area.bn, utils.BengaliDigits(strconv.Itoa(block)), suffix.bn)),
+					Lat:    23.70 + float64(len(locations)%200)*0.001,
+					Lon:    90.30 + float64(len(locations)/200)*0.001,
+				})
+			}
+		}
+	}
+	return locations
+}
+
+// scaleLocations returns the locations repeated scale times with numbered names
+func scaleLocations(locations []models.Location, scale int) []models.Location {
+	dataset := make([]models.Location, 0, len(locations)*scale)
+	for copy := 0; copy < scale; copy++ {
+		for _, location := range locations {
+			location.ID = ""
+			if copy > 0 {
+				location.NameEn = fmt.Sprintf("%s %d", location.NameEn, copy)
+				location.NameBn = fmt.Sprintf("%s %d", location.NameBn, copy)
+				location.Lat += float64(copy) * 1e-5
+			}
+			dataset = append(dataset, location)
+		}
+	}
+	return dataset
+}
+
+// linearSearch is the exact/prefix/contains scan the search index replaced
+func linearSearch(locations []models.Location, query string, limit int) []models.Location {
+	query = strings.ToLower(strings.TrimSpace(query))
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == query },
+		func(name string) bool { return strings.HasPrefix(name, query) },
+		func(name string) bool { return strings.Contains(name, query) },
+	}
+
+	seen := make(map[int]bool)
+	var results []models.Location
+	for _, matches := range matchers {
+		for i, location := range locations {
+			if !seen[i] && (matches(strings.ToLower(location.NameEn)) || matches(strings.ToLower(location.NameBn))) {
+				seen[i] = true
+				results = append(results, location)
+			}
+		}
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func benchmarkSearchLinear(b *testing.B, scale int) {
+	dataset := scaleLocations(benchmarkBaseLocations(b), scale)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearSearch(dataset, benchmarkQueries[i%len(benchmarkQueries)], 20)
+	}
+}
+
+func benchmarkSearchIndexed(b *testing.B, scale int) {
+	service, err := NewLocationServiceFromLocations(scaleLocations(benchmarkBaseLocations(b), scale))
+	if err != nil {
+		b.Fatalf("build location service: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		service.SearchLocations(benchmarkQueries[i%len(benchmarkQueries)], "en", 20)
+	}
+}
+
+func BenchmarkSearchLinear_1x(b *testing.B)    { benchmarkSearchLinear(b, 1) }
+func BenchmarkSearchLinear_10x(b *testing.B)   { benchmarkSearchLinear(b, 10) }
+func BenchmarkSearchLinear_100x(b *testing.B)  { benchmarkSearchLinear(b, 100) }
+func BenchmarkSearchIndexed_1x(b *testing.B)   { benchmarkSearchIndexed(b, 1) }
+func BenchmarkSearchIndexed_10x(b *testing.B)  { benchmarkSearchIndexed(b, 10) }
+func BenchmarkSearchIndexed_100x(b *testing.B) { benchmarkSearchIndexed(b, 100) }
+
+// testSearchLocations is a small dataset with names sharing prefixes and substrings
+var testSearchLocations = []models.Location{
+	{NameEn: "Mirpur 10", NameBn: "মিরপুর ১০"},
+	{NameEn: "Mirpur", NameBn: "মিরপুর"},
+	{NameEn: "Motijheel", NameBn: "মতিঝিল"},
+	{NameEn: "Gulshan", NameBn: "গুলশান"},
+	{NameEn: "Kazipara Mirpur Road", NameBn: "কাজীপাড়া মিরপুর রোড"},
+	{NameEn: "Banani", NameBn: "বনানী"},
+	{NameEn: "Jatrabari", NameBn: "যাত্রাবাড়ী"},
+}
+
+func TestSearchIndexTiers(t *testing.T) {
+	index := newSearchIndex(testSearchLocations)
+
+	tests := []struct {
+		query string
+		want  []string // Name and match type of each result, best first
+	}{
+		{"mirpur", []string{"Mirpur exact", "Mirpur 10 prefix", "Kazipara Mirpur Road contains"}},
+		{"মিরপুর", []string{"Mirpur exact", "Mirpur 10 prefix", "Kazipara Mirpur Road contains"}},
+		{"gul", []string{"Gulshan prefix"}},
+		{"মতি", []string{"Motijheel prefix"}},
+		{"shan", []string{"Gulshan contains"}},
+		{"para mir", []string{"Kazipara Mirpur Road contains"}},
+		{"motijhil", []string{"Motijheel phonetic"}},
+		{"গুলসান", []string{"Gulshan phonetic"}},
+		{"bonani", []string{"Banani phonetic"}},
+		{"jatrab", []string{"Jatrabari prefix"}},
+		{"motizheel", []string{"Motijheel fuzzy"}},
+		{"xyz", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, match := range index.search(tt.query, fieldEn, 10) {
+				got = append(got, testSearchLocations[match.id].NameEn+" "+string(match.matchType))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("search(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIndexScoresAndLimit(t *testing.T) {
+	index := newSearchIndex(testSearchLocations)
+
+	matches := index.search("mirpur", fieldEn, 2)
+	if len(matches) != 2 {
+		t.Fatalf("got %d matches, want the limit of 2", len(matches))
+	}
+	if matches[0].score != 1.0 || matches[1].score != 0.9 {
+		t.Errorf("scores = %v, %v; want 1 then 0.9", matches[0].score, matches[1].score)
+	}
+
+	// Fuzzy matches score below every other tier
+	for _, match := range index.search("motizheel", fieldEn, 10) {
+		if match.score <= 0 || match.score >= 0.72 {
+			t.Errorf("fuzzy score = %v, want between 0 and the phonetic prefix score", match.score)
+		}
+	}
+}
+
+func TestSearchIndexFindsLinearScanMatches(t *testing.T) {
+	// Every exact, prefix or substring match of a linear scan must be found by the index
+	locations := scaleLocations(testSearchLocations, 3)
+	index := newSearchIndex(locations)
+	for _, query := range []string{"mirpur", "pur", "মিরপুর", "road", "i", "2"} {
+		found := make(map[string]bool)
+		for _, match := range index.search(query, fieldEn, len(locations)) {
+			found[locations[match.id].NameEn] = true
+		}
+		for _, location := range linearSearch(locations, query, len(locations)) {
+			if !found[location.NameEn] {
+				t.Errorf("search(%q) missed %q", query, location.NameEn)
+			}
+		}
+	}
+}
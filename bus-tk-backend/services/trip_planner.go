@@ -0,0 +1,455 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// Trip planner tuning
+const (
+	maxAccessWalkMeters        = 1000.0 // Longest walk to the first stop or from the last stop
+	maxTransferWalkMeters      = 400.0  // Longest walk between two stops when transferring
+	maxTripTransfers           = 2
+	walkCostFactor             = 2.0    // A meter walked costs as much as two meters ridden
+	boardingCostMeters         = 2000.0 // Penalty per boarding, so fewer transfers rank higher
+	maxItinerariesPerTransfers = 3      // Alternatives returned per number of transfers
+)
+
+// TripPlanner finds bus itineraries between two locations over the route network.
+// It runs RAPTOR-style rounds: round k holds the best ways to reach each stop with
+// k bus rides, and each round extends the previous one by one ride plus an optional
+// short walk to a nearby stop. Without timetables, cost is the ridden distance plus
+// weighted walking distance and a penalty per boarding. Routes are ridden in both
+// directions, as models.Route describes.
+type TripPlanner struct {
+	routeService *RouteService
+	fareService  *FareService
+	roadFactor   float64
+	stops        []models.Stop
+	stopIndex    *kdTree                   // Nearest-neighbour index over stops
+	patterns     map[string]routePattern   // Route ID to its stop distances
+	footpaths    map[string][]tripFootpath // Stop ID to the stops within transfer distance
+}
+
+// routePattern holds the position and cumulative distance of each stop along a route
+type routePattern struct {
+	route        models.Route
+	positions    map[string]int // Stop ID to its first position on the route
+	cumulativeKm []float64
+}
+
+// tripFootpath is a walk between two nearby stops
+type tripFootpath struct {
+	stopID string
+	meters float64
+}
+
+// tripLabelKind identifies the leg that led to a label
+type tripLabelKind int
+
+const (
+	labelAccess tripLabelKind = iota // Walked from the start location
+	labelRide                        // Rode a bus from the parent label's stop
+	labelWalk                        // Walked from the parent label's stop to transfer
+)
+
+// tripLabel is a way of reaching a stop, linked to the label it continues from
+type tripLabel struct {
+	kind    tripLabelKind
+	stopID  string
+	routeID string // Route of the last bus ride; empty before the first ride
+	cost    float64
+	meters  float64 // Length of the leg leading to this label
+	stops   int     // Stops ridden, for ride labels
+	rides   int
+	parent  *tripLabel
+}
+
+// tripCandidate is a complete path to the destination
+type tripCandidate struct {
+	last         *tripLabel
+	egressMeters float64
+	cost         float64
+}
+
+// NewTripPlanner creates a trip planner over the routes of the route service
+func NewTripPlanner(routeService *RouteService, fareService *FareService, roadFactor float64) *TripPlanner {
+	planner := &TripPlanner{
+		routeService: routeService,
+		fareService:  fareService,
+		roadFactor:   roadFactor,
+		stops:        routeService.allStops(),
+		patterns:     make(map[string]routePattern),
+		footpaths:    make(map[string][]tripFootpath),
+	}
+
+	lats := make([]float64, len(planner.stops))
+	lons := make([]float64, len(planner.stops))
+	for i, stop := range planner.stops {
+		lats[i] = stop.Lat
+		lons[i] = stop.Lon
+	}
+	planner.stopIndex = newKDTree(lats, lons)
+
+	for _, routeID := range routeService.routeIDs {
+		route := routeService.routes[routeID]
+		pattern := routePattern{
+			route:        route,
+			positions:    make(map[string]int),
+			cumulativeKm: make([]float64, len(route.StopIDs)),
+		}
+		for i, stopID := range route.StopIDs {
+			if _, seen := pattern.positions[stopID]; !seen {
+				pattern.positions[stopID] = i
+			}
+			if i > 0 {
+				previous := routeService.stops[route.StopIDs[i-1]]
+				current := routeService.stops[stopID]
+				pattern.cumulativeKm[i] = pattern.cumulativeKm[i-1] + planner.roadMeters(previous.Lat, previous.Lon, current.Lat, current.Lon)/1000
+			}
+		}
+		planner.patterns[routeID] = pattern
+	}
+
+	for _, stop := range planner.stops {
+		for _, nearby := range planner.stopsWithin(stop.Lat, stop.Lon, maxTransferWalkMeters) {
+			if nearby.stopID != stop.ID {
+				planner.footpaths[stop.ID] = append(planner.footpaths[stop.ID], nearby)
+			}
+		}
+	}
+
+	return planner
+}
+
+// roadMeters estimates the street distance between two coordinates
+func (p *TripPlanner) roadMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	return utils.HaversineMeters(lat1, lon1, lat2, lon2) * p.roadFactor
+}
+
+// stopsWithin returns the stops within walking distance of a coordinate, sorted by stop ID
+func (p *TripPlanner) stopsWithin(lat, lon, maxMeters float64) []tripFootpath {
+	var nearby []tripFootpath
+	// The index works in straight-line meters, so search the radius before the road factor
+	for _, index := range p.stopIndex.withinRadius(lat, lon, maxMeters/p.roadFactor) {
+		stop := p.stops[index]
+		meters := p.roadMeters(lat, lon, stop.Lat, stop.Lon)
+		if meters <= maxMeters {
+			nearby = append(nearby, tripFootpath{stopID: stop.ID, meters: meters})
+		}
+	}
+	sort.Slice(nearby, func(i, j int) bool {
+		return nearby[i].stopID < nearby[j].stopID
+	})
+	return nearby
+}
+
+// Plan returns itineraries between the request's locations, direct buses first
+func (p *TripPlanner) Plan(request models.TripPlanRequest) (*models.TripPlanResponse, error) {
+	start, end := request.StartLocation, request.EndLocation
+	if !hasCoordinates(start) || !hasCoordinates(end) {
+		return nil, fmt.Errorf("invalid request: start and end locations with coordinates are required")
+	}
+	if _, err := departureTime(request.DepartureTime); err != nil {
+		return nil, err
+	}
+	if _, err := p.fareService.resolveDiscounts(request.DiscountType, request.DiscountTypes); err != nil {
+		return nil, err
+	}
+
+	egress := make(map[string]float64)
+	for _, nearby := range p.stopsWithin(end.Lat, end.Lon, maxAccessWalkMeters) {
+		egress[nearby.stopID] = nearby.meters
+	}
+
+	// Round 0: walk from the start to every stop within reach
+	previous := make(map[string]*tripLabel)
+	bestAtStop := make(map[string]float64)
+	for _, nearby := range p.stopsWithin(start.Lat, start.Lon, maxAccessWalkMeters) {
+		label := &tripLabel{kind: labelAccess, stopID: nearby.stopID, cost: nearby.meters * walkCostFactor, meters: nearby.meters}
+		previous[labelKey(label)] = label
+		bestAtStop[nearby.stopID] = label.cost
+	}
+
+	var candidates []tripCandidate
+	for round := 1; round <= maxTripTransfers+1 && len(previous) > 0; round++ {
+		current := make(map[string]*tripLabel)
+
+		// Ride one more bus from every label of the previous round
+		for _, label := range sortedLabels(previous) {
+			for _, routeID := range p.routeService.routesByStop[label.stopID] {
+				if routeID == label.routeID {
+					continue
+				}
+				pattern := p.patterns[routeID]
+				boardAt := pattern.positions[label.stopID]
+				for alightAt, stopID := range pattern.route.StopIDs {
+					rideKm := math.Abs(pattern.cumulativeKm[alightAt] - pattern.cumulativeKm[boardAt])
+					if alightAt == boardAt || rideKm == 0 {
+						continue
+					}
+					improveLabel(current, bestAtStop, &tripLabel{
+						kind:    labelRide,
+						stopID:  stopID,
+						routeID: routeID,
+						cost:    label.cost + boardingCostMeters + rideKm*1000,
+						meters:  rideKm * 1000,
+						stops:   absInt(alightAt - boardAt),
+						rides:   label.rides + 1,
+						parent:  label,
+					})
+				}
+			}
+		}
+
+		// Walk from where a bus was left to nearby stops for the next transfer
+		for _, label := range sortedLabels(current) {
+			if label.kind != labelRide {
+				continue
+			}
+			for _, footpath := range p.footpaths[label.stopID] {
+				improveLabel(current, bestAtStop, &tripLabel{
+					kind:    labelWalk,
+					stopID:  footpath.stopID,
+					routeID: label.routeID,
+					cost:    label.cost + footpath.meters*walkCostFactor,
+					meters:  footpath.meters,
+					rides:   label.rides,
+					parent:  label,
+				})
+			}
+		}
+
+		for _, label := range sortedLabels(current) {
+			if meters, ok := egress[label.stopID]; ok && label.kind == labelRide {
+				candidates = append(candidates, tripCandidate{last: label, egressMeters: meters, cost: label.cost + meters*walkCostFactor})
+			}
+			if cost, ok := bestAtStop[label.stopID]; !ok || label.cost < cost {
+				bestAtStop[label.stopID] = label.cost
+			}
+		}
+		previous = current
+	}
+
+	return p.buildResponse(request, candidates)
+}
+
+// improveLabel stores a label unless an earlier round or the same route already reaches the stop cheaper
+func improveLabel(labels map[string]*tripLabel, bestAtStop map[string]float64, label *tripLabel) {
+	if best, ok := bestAtStop[label.stopID]; ok && best <= label.cost {
+		return
+	}
+	key := labelKey(label)
+	if existing, ok := labels[key]; ok && existing.cost <= label.cost {
+		return
+	}
+	labels[key] = label
+}
+
+// labelKey identifies a label by stop and last route, so different routes to a stop are kept apart
+func labelKey(label *tripLabel) string {
+	return label.stopID + "|" + label.routeID
+}
+
+// sortedLabels returns the labels in a deterministic order
+func sortedLabels(labels map[string]*tripLabel) []*tripLabel {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sorted := make([]*tripLabel, len(keys))
+	for i, key := range keys {
+		sorted[i] = labels[key]
+	}
+	return sorted
+}
+
+// buildResponse turns the candidates into priced itineraries, fewest transfers first
+func (p *TripPlanner) buildResponse(request models.TripPlanRequest, candidates []tripCandidate) (*models.TripPlanResponse, error) {
+	// Keep the cheapest candidate for each sequence of routes
+	bySignature := make(map[string]tripCandidate)
+	for _, candidate := range candidates {
+		signature := candidateSignature(candidate.last)
+		if existing, ok := bySignature[signature]; !ok || candidate.cost < existing.cost {
+			bySignature[signature] = candidate
+		}
+	}
+
+	unique := make([]tripCandidate, 0, len(bySignature))
+	for _, candidate := range bySignature {
+		unique = append(unique, candidate)
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].last.rides != unique[j].last.rides {
+			return unique[i].last.rides < unique[j].last.rides
+		}
+		if unique[i].cost != unique[j].cost {
+			return unique[i].cost < unique[j].cost
+		}
+		return candidateSignature(unique[i].last) < candidateSignature(unique[j].last)
+	})
+
+	itineraries := make([]models.Itinerary, 0)
+	perTransferCount := make(map[int]int)
+	for _, candidate := range unique {
+		transfers := candidate.last.rides - 1
+		if perTransferCount[transfers] >= maxItinerariesPerTransfers {
+			continue
+		}
+		perTransferCount[transfers]++
+
+		itinerary, err := p.buildItinerary(request, candidate)
+		if err != nil {
+			return nil, err
+		}
+		itineraries = append(itineraries, *itinerary)
+	}
+
+	return &models.TripPlanResponse{
+		Itineraries: itineraries,
+		Total:       len(itineraries),
+	}, nil
+}
+
+// candidateSignature describes the routes ridden by a candidate
+func candidateSignature(label *tripLabel) string {
+	var routeIDs []string
+	for ; label != nil; label = label.parent {
+		if label.kind == labelRide {
+			routeIDs = append([]string{label.routeID}, routeIDs...)
+		}
+	}
+	return strings.Join(routeIDs, ">")
+}
+
+// buildItinerary converts a candidate's labels into walking and priced bus legs
+func (p *TripPlanner) buildItinerary(request models.TripPlanRequest, candidate tripCandidate) (*models.Itinerary, error) {
+	var labels []*tripLabel
+	for label := candidate.last; label != nil; label = label.parent {
+		labels = append([]*tripLabel{label}, labels...)
+	}
+
+	itinerary := &models.Itinerary{Transfers: candidate.last.rides - 1}
+	addWalk := func(from, to, fromStopID, toStopID string, meters float64) {
+		if meters < 1 {
+			return
+		}
+		itinerary.Legs = append(itinerary.Legs, models.TripLeg{
+			Mode:           string(models.TripLegModeWalk),
+			From:           from,
+			To:             to,
+			FromStopID:     fromStopID,
+			ToStopID:       toStopID,
+			DistanceMeters: math.Round(meters),
+		})
+		itinerary.WalkingMeters += math.Round(meters)
+	}
+
+	for _, label := range labels {
+		stop := p.routeService.stops[label.stopID]
+		switch label.kind {
+		case labelAccess:
+			addWalk(locationName(request.StartLocation, "Start"), stop.NameEn, "", stop.ID, label.meters)
+		case labelWalk:
+			from := p.routeService.stops[label.parent.stopID]
+			addWalk(from.NameEn, stop.NameEn, from.ID, stop.ID, label.meters)
+		case labelRide:
+			leg := p.busLeg(label)
+			itinerary.Legs = append(itinerary.Legs, leg)
+			itinerary.BusDistanceKm += leg.DistanceMeters / 1000
+		}
+	}
+
+	lastStop := p.routeService.stops[candidate.last.stopID]
+	addWalk(lastStop.NameEn, locationName(request.EndLocation, "Destination"), lastStop.ID, "", candidate.egressMeters)
+
+	itinerary.BusDistanceKm = math.Round(itinerary.BusDistanceKm*100) / 100
+	if err := p.priceItinerary(request, itinerary); err != nil {
+		return nil, err
+	}
+	return itinerary, nil
+}
+
+// busLeg builds an unpriced bus leg
+func (p *TripPlanner) busLeg(label *tripLabel) models.TripLeg {
+	summary := p.routeService.summarize(p.routeService.routes[label.routeID])
+	from := p.routeService.stops[label.parent.stopID]
+	to := p.routeService.stops[label.stopID]
+
+	return models.TripLeg{
+		Mode:           string(models.TripLegModeBus),
+		From:           from.NameEn,
+		To:             to.NameEn,
+		FromStopID:     from.ID,
+		ToStopID:       to.ID,
+		RouteID:        summary.ID,
+		RouteName:      summary.NameEn,
+		OperatorName:   summary.Operator.NameEn,
+		BusType:        summary.BusType,
+		Stops:          label.stops,
+		DistanceMeters: math.Round(label.meters),
+	}
+}
+
+// priceItinerary prices the bus legs together, so transfer rules and the
+// discount apply across the whole journey
+func (p *TripPlanner) priceItinerary(request models.TripPlanRequest, itinerary *models.Itinerary) error {
+	fareRequest := models.MultiLegFareRequest{
+		DiscountType:  request.DiscountType,
+		DiscountTypes: request.DiscountTypes,
+		DepartureTime: request.DepartureTime,
+	}
+	var distances []float64
+	var busLegs []int
+	for i, leg := range itinerary.Legs {
+		if leg.Mode != string(models.TripLegModeBus) {
+			continue
+		}
+		distance := leg.DistanceMeters / 1000
+		fareRequest.Legs = append(fareRequest.Legs, models.FareRequest{
+			Distance:      distance,
+			BusType:       leg.BusType,
+			OperatorID:    p.routeService.routes[leg.RouteID].OperatorID,
+			RouteID:       leg.RouteID,
+			BoardingStop:  leg.FromStopID,
+			AlightingStop: leg.ToStopID,
+		})
+		distances = append(distances, distance)
+		busLegs = append(busLegs, i)
+	}
+
+	fares, err := p.fareService.CalculateMultiLegFare(fareRequest, distances)
+	if err != nil {
+		return fmt.Errorf("failed to price itinerary: %v", err)
+	}
+	for i, legIndex := range busLegs {
+		fare := fares.Legs[i]
+		fare.DistanceSource = string(models.DistanceSourceEstimated)
+		itinerary.Legs[legIndex].Fare = &fare
+	}
+	itinerary.TotalFare = fares.TotalFare
+	itinerary.TransferDiscount = fares.TransferDiscount
+	return nil
+}
+
+// locationName returns the English name of a location or a fallback
+func locationName(location models.Location, fallback string) string {
+	if location.NameEn != "" {
+		return location.NameEn
+	}
+	return fallback
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// testTripNetwork lays out stops along one street, about 2 km apart: route r1
+// runs west-a-b-c, r2 runs from c2, a short walk east of c, to d and e. Extra
+// routes are added as given.
+func testTripNetwork(extra ...models.Route) models.RouteNetwork {
+	stop := func(id string, lon float64) models.Stop {
+		return models.Stop{ID: id, NameEn: strings.ToUpper(id), NameBn: id, Lat: 23.75, Lon: lon}
+	}
+	return models.RouteNetwork{
+		Operators: []models.Operator{{ID: "op", NameEn: "Test Transit"}},
+		Stops: []models.Stop{
+			stop("a", 90.35), stop("b", 90.37), stop("c", 90.39),
+			stop("c2", 90.3918), stop("d", 90.41), stop("e", 90.43),
+		},
+		Routes: append([]models.Route{
+			{ID: "r1", OperatorID: "op", NameEn: "A–C", BusType: "nonAC", StopIDs: []string{"a", "b", "c"}},
+			{ID: "r2", OperatorID: "op", NameEn: "C2–E", BusType: "nonAC", StopIDs: []string{"c2", "d", "e"}},
+		}, extra...),
+	}
+}
+
+// newTestTripPlanner creates a planner with straight-line distances and a Tk 5 transfer discount
+func newTestTripPlanner(t *testing.T, network models.RouteNetwork) *TripPlanner {
+	t.Helper()
+	routeService, err := NewRouteService(network)
+	if err != nil {
+		t.Fatalf("NewRouteService: %v", err)
+	}
+	table := testFareTable()
+	table.TransferRules = []models.TransferRule{{Name: "Any transfer", DiscountAmount: 5}}
+	return NewTripPlanner(routeService, newTestFareService(t, table), 1)
+}
+
+// tripPlanRequest goes from just west of stop a to just east of stop e
+func tripPlanRequest() models.TripPlanRequest {
+	return models.TripPlanRequest{
+		StartLocation: models.Location{NameEn: "Home", Lat: 23.75, Lon: 90.348},
+		EndLocation:   models.Location{NameEn: "Office", Lat: 23.75, Lon: 90.432},
+		DepartureTime: testDeparture,
+	}
+}
+
+// describeItinerary lists an itinerary's legs, e.g. "walk>r1>walk"
+func describeItinerary(itinerary models.Itinerary) string {
+	var legs []string
+	for _, leg := range itinerary.Legs {
+		if leg.Mode == string(models.TripLegModeBus) {
+			legs = append(legs, leg.RouteID)
+		} else {
+			legs = append(legs, leg.Mode)
+		}
+	}
+	return strings.Join(legs, ">")
+}
+
+func TestPlanWithTransfer(t *testing.T) {
+	planner := newTestTripPlanner(t, testTripNetwork())
+
+	response, err := planner.Plan(tripPlanRequest())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if response.Total != 1 {
+		t.Fatalf("got %d itineraries, want 1", response.Total)
+	}
+
+	itinerary := response.Itineraries[0]
+	if got := describeItinerary(itinerary); got != "walk>r1>walk>r2>walk" {
+		t.Errorf("legs = %s, want a walk to a, r1 to c, a walk to c2 and r2 to e", got)
+	}
+	if itinerary.Transfers != 1 {
+		t.Errorf("transfers = %d, want 1", itinerary.Transfers)
+	}
+
+	first, second := itinerary.Legs[1], itinerary.Legs[3]
+	if first.FromStopID != "a" || first.ToStopID != "c" || first.Stops != 2 {
+		t.Errorf("first ride = %s to %s over %d stops, want a to c over 2", first.FromStopID, first.ToStopID, first.Stops)
+	}
+	if second.FromStopID != "c2" || second.ToStopID != "e" || second.Stops != 2 {
+		t.Errorf("second ride = %s to %s over %d stops, want c2 to e over 2", second.FromStopID, second.ToStopID, second.Stops)
+	}
+
+	// The legs are priced together, so the transfer rule applies to the second ride
+	if first.Fare == nil || second.Fare == nil {
+		t.Fatal("bus legs are not priced")
+	}
+	if second.Fare.TransferRule != "Any transfer" || itinerary.TransferDiscount != 5 {
+		t.Errorf("transfer rule %q, discount %v; want Any transfer, 5", second.Fare.TransferRule, itinerary.TransferDiscount)
+	}
+	if total := first.Fare.Fare + second.Fare.Fare; total != itinerary.TotalFare {
+		t.Errorf("total fare = %v, legs add up to %v", itinerary.TotalFare, total)
+	}
+
+	var walked float64
+	for _, leg := range itinerary.Legs {
+		if leg.Mode == string(models.TripLegModeWalk) {
+			walked += leg.DistanceMeters
+		}
+	}
+	if walked != itinerary.WalkingMeters || walked > 3*maxTransferWalkMeters {
+		t.Errorf("walking = %v m, legs add up to %v m", itinerary.WalkingMeters, walked)
+	}
+}
+
+func TestPlanAgainstStopOrder(t *testing.T) {
+	planner := newTestTripPlanner(t, testTripNetwork())
+	forward, err := planner.Plan(tripPlanRequest())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	// The return trip rides r2 from e back to c2 and r1 from c back to a
+	request := tripPlanRequest()
+	request.StartLocation, request.EndLocation = request.EndLocation, request.StartLocation
+	response, err := planner.Plan(request)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if response.Total != 1 {
+		t.Fatalf("got %d itineraries, want 1", response.Total)
+	}
+
+	itinerary := response.Itineraries[0]
+	if got := describeItinerary(itinerary); got != "walk>r2>walk>r1>walk" {
+		t.Errorf("legs = %s, want r2 and then r1", got)
+	}
+	first, second := itinerary.Legs[1], itinerary.Legs[3]
+	if first.FromStopID != "e" || first.ToStopID != "c2" || first.Stops != 2 {
+		t.Errorf("first ride = %s to %s over %d stops, want e to c2 over 2", first.FromStopID, first.ToStopID, first.Stops)
+	}
+	if second.FromStopID != "c" || second.ToStopID != "a" || second.Stops != 2 {
+		t.Errorf("second ride = %s to %s over %d stops, want c to a over 2", second.FromStopID, second.ToStopID, second.Stops)
+	}
+	if itinerary.BusDistanceKm != forward.Itineraries[0].BusDistanceKm || itinerary.TotalFare != forward.Itineraries[0].TotalFare {
+		t.Errorf("return trip rides %v km for %v, want the %v km and %v of the forward trip",
+			itinerary.BusDistanceKm, itinerary.TotalFare, forward.Itineraries[0].BusDistanceKm, forward.Itineraries[0].TotalFare)
+	}
+}
+
+func TestPlanDirectRoutes(t *testing.T) {
+	north := models.Stop{ID: "n", NameEn: "N", NameBn: "n", Lat: 23.80, Lon: 90.39}
+
+	tests := []struct {
+		name   string
+		direct models.Route
+		want   []string
+	}{
+		// A transfer is only offered when it costs less than riding straight through
+		{"direct ride dominates", models.Route{ID: "r3", OperatorID: "op", BusType: "AC", StopIDs: []string{"e", "d", "a"}}, []string{"walk>r3>walk"}},
+		{"detour listed first", models.Route{ID: "r3", OperatorID: "op", BusType: "AC", StopIDs: []string{"a", "n", "e"}}, []string{"walk>r3>walk", "walk>r1>walk>r2>walk"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := testTripNetwork(tt.direct)
+			network.Stops = append(network.Stops, north)
+			response, err := newTestTripPlanner(t, network).Plan(tripPlanRequest())
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+
+			var got []string
+			for _, itinerary := range response.Itineraries {
+				got = append(got, describeItinerary(itinerary))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("itineraries = %v, want %v", got, tt.want)
+			}
+			if direct := response.Itineraries[0]; direct.Transfers != 0 || direct.Legs[1].BusType != "AC" {
+				t.Errorf("direct itinerary = %+v", direct)
+			}
+		})
+	}
+}
+
+func TestPlanWithoutRoute(t *testing.T) {
+	planner := newTestTripPlanner(t, testTripNetwork())
+
+	// Far from every stop
+	request := tripPlanRequest()
+	request.StartLocation = models.Location{Lat: 23.90, Lon: 90.35}
+	response, err := planner.Plan(request)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if response.Total != 0 || response.Itineraries == nil {
+		t.Errorf("got %+v, want an empty list", response)
+	}
+
+	// Beyond the transfer limit: l1 a-b, l2 b-c, l3 c-d and l4 d-e need three transfers
+	chain := models.RouteNetwork{
+		Operators: []models.Operator{{ID: "op"}},
+		Routes: []models.Route{
+			{ID: "l1", OperatorID: "op", BusType: "nonAC", StopIDs: []string{"a", "b"}},
+			{ID: "l2", OperatorID: "op", BusType: "nonAC", StopIDs: []string{"b", "c"}},
+			{ID: "l3", OperatorID: "op", BusType: "nonAC", StopIDs: []string{"c", "d"}},
+			{ID: "l4", OperatorID: "op", BusType: "nonAC", StopIDs: []string{"d", "e"}},
+		},
+	}
+	for _, stop := range testTripNetwork().Stops {
+		if stop.ID != "c2" {
+			chain.Stops = append(chain.Stops, stop)
+		}
+	}
+	response, err = newTestTripPlanner(t, chain).Plan(tripPlanRequest())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if response.Total != 0 {
+		t.Errorf("got %d itineraries needing more than %d transfers", response.Total, maxTripTransfers)
+	}
+}
+
+func TestPlanRejectsInvalidRequests(t *testing.T) {
+	planner := newTestTripPlanner(t, testTripNetwork())
+
+	tests := []struct {
+		name   string
+		modify func(request *models.TripPlanRequest)
+	}{
+		{"no start coordinates", func(request *models.TripPlanRequest) { request.StartLocation = models.Location{NameEn: "Home"} }},
+		{"bad departure time", func(request *models.TripPlanRequest) { request.DepartureTime = "tomorrow" }},
+		{"unknown discount", func(request *models.TripPlanRequest) { request.DiscountType = "pensioner" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tripPlanRequest()
+			tt.modify(&request)
+			if _, err := planner.Plan(request); err == nil {
+				t.Error("Plan accepted the request")
+			}
+		})
+	}
+}
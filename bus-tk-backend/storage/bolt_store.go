@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// locationsBucket holds one JSON-encoded location per key. Keys are the big-endian
+// positions of the locations, so a cursor returns them in dataset order.
+var locationsBucket = []byte("locations")
+
+// holidaysBucket holds one JSON-encoded holiday per key, keyed like locationsBucket
+var holidaysBucket = []byte("holidays")
+
+// BoltStore keeps the locations and the holiday calendar in a bbolt database file
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens or creates the database at path
+func OpenBoltStore(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %v", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open location database: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{locationsBucket, holidaysBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize location database: %v", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// LoadLocations reads every location in dataset order
+func (s *BoltStore) LoadLocations() ([]models.Location, error) {
+	locations := []models.Location{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(locationsBucket).ForEach(func(key, value []byte) error {
+			var location models.Location
+			if err := json.Unmarshal(value, &location); err != nil {
+				return fmt.Errorf("location %d: %v", binary.BigEndian.Uint64(key), err)
+			}
+			locations = append(locations, location)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locations: %v", err)
+	}
+	return locations, nil
+}
+
+// SaveLocations replaces the stored locations in a single transaction
+func (s *BoltStore) SaveLocations(locations []models.Location) error {
+	if err := replaceBucket(s.db, locationsBucket, locations); err != nil {
+		return fmt.Errorf("failed to save locations: %v", err)
+	}
+	return nil
+}
+
+// LoadHolidays reads the holiday calendar in calendar order
+func (s *BoltStore) LoadHolidays() (*models.HolidayCalendar, error) {
+	calendar := &models.HolidayCalendar{Holidays: []models.Holiday{}}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(holidaysBucket).ForEach(func(key, value []byte) error {
+			var holiday models.Holiday
+			if err := json.Unmarshal(value, &holiday); err != nil {
+				return fmt.Errorf("holiday %d: %v", binary.BigEndian.Uint64(key), err)
+			}
+			calendar.Holidays = append(calendar.Holidays, holiday)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holiday calendar: %v", err)
+	}
+	return calendar, nil
+}
+
+// SaveHolidays replaces the stored holiday calendar in a single transaction
+func (s *BoltStore) SaveHolidays(calendar *models.HolidayCalendar) error {
+	if err := replaceBucket(s.db, holidaysBucket, calendar.Holidays); err != nil {
+		return fmt.Errorf("failed to save holiday calendar: %v", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// replaceBucket replaces the contents of a bucket with the JSON-encoded values,
+// keyed by their big-endian positions
+func replaceBucket[T any](db *bolt.DB, name []byte, values []T) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket(name)
+		if err != nil {
+			return err
+		}
+
+		for i, v := range values {
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			// Keys must stay valid until the transaction commits, so each gets its own slice
+			key := binary.BigEndian.AppendUint64(nil, uint64(i))
+			if err := bucket.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
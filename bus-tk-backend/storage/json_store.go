@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+	"github.com/spectrum/bus-tk-backend/utils"
+)
+
+// JSONStore keeps the locations as a JSON array in a file
+type JSONStore struct {
+	path string
+}
+
+// NewJSONStore creates a store over the JSON file at path
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{path: path}
+}
+
+// LoadLocations reads the locations from the file
+func (s *JSONStore) LoadLocations() ([]models.Location, error) {
+	jsonData, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locations: %v", err)
+	}
+
+	var locations []models.Location
+	if err := json.Unmarshal(jsonData, &locations); err != nil {
+		return nil, fmt.Errorf("failed to parse locations: %v", err)
+	}
+
+	return locations, nil
+}
+
+// SaveLocations atomically replaces the file
+func (s *JSONStore) SaveLocations(locations []models.Location) error {
+	if err := utils.WriteJSONFile(s.path, locations); err != nil {
+		return fmt.Errorf("failed to save locations: %v", err)
+	}
+	return nil
+}
+
+// ModTime returns the modification time of the file
+func (s *JSONStore) ModTime() (time.Time, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// Close does nothing; the file is only open while loading or saving
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+// JSONHolidayStore keeps the holiday calendar in a JSON file
+type JSONHolidayStore struct {
+	path string
+}
+
+// NewJSONHolidayStore creates a store over the JSON file at path
+func NewJSONHolidayStore(path string) *JSONHolidayStore {
+	return &JSONHolidayStore{path: path}
+}
+
+// LoadHolidays reads the holiday calendar from the file
+func (s *JSONHolidayStore) LoadHolidays() (*models.HolidayCalendar, error) {
+	jsonData, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holiday calendar: %v", err)
+	}
+
+	var calendar models.HolidayCalendar
+	if err := json.Unmarshal(jsonData, &calendar); err != nil {
+		return nil, fmt.Errorf("failed to parse holiday calendar: %v", err)
+	}
+
+	return &calendar, nil
+}
+
+// SaveHolidays atomically replaces the file
+func (s *JSONHolidayStore) SaveHolidays(calendar *models.HolidayCalendar) error {
+	if err := utils.WriteJSONFile(s.path, calendar); err != nil {
+		return fmt.Errorf("failed to save holiday calendar: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Package storage persists the data admins edit at runtime, the location dataset
+// and the holiday calendar, behind common interfaces, so the server can keep it in
+// JSON files or in an embedded bbolt database. Fare tables, the fare chart and
+// discounts are only changed by deploying new files, so they stay as JSON files
+// outside this package.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// LocationStore loads and saves the location dataset
+type LocationStore interface {
+	// LoadLocations returns every stored location in order
+	LoadLocations() ([]models.Location, error)
+	// SaveLocations replaces the stored locations
+	SaveLocations(locations []models.Location) error
+	Close() error
+}
+
+// HolidayStore loads and saves the holiday calendar used by fare adjustments
+type HolidayStore interface {
+	// LoadHolidays returns the stored calendar
+	LoadHolidays() (*models.HolidayCalendar, error)
+	// SaveHolidays replaces the stored calendar
+	SaveHolidays(calendar *models.HolidayCalendar) error
+}
+
+// Watchable is implemented by stores that can be changed outside the server, such
+// as a JSON file edited by hand, so they can be polled for changes
+type Watchable interface {
+	// ModTime returns when the stored locations last changed
+	ModTime() (time.Time, error)
+}
+
+// Kind represents a location store implementation
+type Kind string
+
+const (
+	KindJSON Kind = "json" // JSON array in a file, the format of data/dhaka_areas.json
+	KindBolt Kind = "bolt" // bbolt database file
+)
+
+// Open opens the location store of the given kind. A bolt store that is still
+// empty is seeded from the JSON file at jsonPath when that file exists.
+func Open(kind Kind, jsonPath, boltPath string) (LocationStore, error) {
+	switch kind {
+	case KindJSON:
+		return NewJSONStore(jsonPath), nil
+	case KindBolt:
+		store, err := OpenBoltStore(boltPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := seed(store, NewJSONStore(jsonPath)); err != nil {
+			store.Close()
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown location store %q", kind)
+	}
+}
+
+// OpenHolidays returns the holiday store kept alongside a location store: the same
+// bolt database, seeded from the JSON file at jsonPath while it has no holidays, or
+// else the JSON file itself. The bolt database is closed with the location store.
+func OpenHolidays(locations LocationStore, jsonPath string) (HolidayStore, error) {
+	store, ok := locations.(*BoltStore)
+	if !ok {
+		return NewJSONHolidayStore(jsonPath), nil
+	}
+	if err := seedHolidays(store, NewJSONHolidayStore(jsonPath)); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// seed copies the JSON locations into an empty store
+func seed(store LocationStore, source *JSONStore) error {
+	locations, err := store.LoadLocations()
+	if err != nil || len(locations) > 0 {
+		return err
+	}
+
+	if _, err := os.Stat(source.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	locations, err = source.LoadLocations()
+	if err != nil {
+		return err
+	}
+	return store.SaveLocations(locations)
+}
+
+// seedHolidays copies the JSON holiday calendar into a store without holidays
+func seedHolidays(store HolidayStore, source *JSONHolidayStore) error {
+	calendar, err := store.LoadHolidays()
+	if err != nil || len(calendar.Holidays) > 0 {
+		return err
+	}
+
+	if _, err := os.Stat(source.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	calendar, err = source.LoadHolidays()
+	if err != nil {
+		return err
+	}
+	return store.SaveHolidays(calendar)
+}
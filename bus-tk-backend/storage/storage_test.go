@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spectrum/bus-tk-backend/models"
+)
+
+// testLocations returns n numbered locations, enough to span several key bytes
+func testLocations(n int) []models.Location {
+	locations := make([]models.Location, n)
+	for i := range locations {
+		locations[i] = models.Location{
+			ID:     fmt.Sprintf("place-%d", i),
+			NameEn: fmt.Sprintf("Place %d", i),
+			NameBn: fmt.Sprintf("স্থান %d", i),
+			Lat:    23.7 + float64(i)*1e-4,
+			Lon:    90.4,
+		}
+	}
+	return locations
+}
+
+func TestStoresRoundTrip(t *testing.T) {
+	stores := map[Kind]func(t *testing.T) LocationStore{
+		KindJSON: func(t *testing.T) LocationStore {
+			return NewJSONStore(filepath.Join(t.TempDir(), "locations.json"))
+		},
+		KindBolt: func(t *testing.T) LocationStore {
+			store, err := OpenBoltStore(filepath.Join(t.TempDir(), "db", "locations.db"))
+			if err != nil {
+				t.Fatalf("OpenBoltStore: %v", err)
+			}
+			return store
+		},
+	}
+
+	for kind, open := range stores {
+		t.Run(string(kind), func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			// More than 256 locations, so positions need more than one key byte
+			locations := testLocations(300)
+			if err := store.SaveLocations(locations); err != nil {
+				t.Fatalf("SaveLocations: %v", err)
+			}
+			loaded, err := store.LoadLocations()
+			if err != nil {
+				t.Fatalf("LoadLocations: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, locations) {
+				t.Errorf("loaded %d locations, not the %d saved in order", len(loaded), len(locations))
+			}
+
+			// Saving a shorter dataset drops the rest
+			if err := store.SaveLocations(locations[:2]); err != nil {
+				t.Fatalf("SaveLocations: %v", err)
+			}
+			loaded, err = store.LoadLocations()
+			if err != nil {
+				t.Fatalf("LoadLocations: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, locations[:2]) {
+				t.Errorf("loaded %+v after replacing, want the first 2", loaded)
+			}
+		})
+	}
+}
+
+func TestOpenBoltSeedsFromJSON(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath, boltPath := filepath.Join(dir, "locations.json"), filepath.Join(dir, "locations.db")
+	if err := NewJSONStore(jsonPath).SaveLocations(testLocations(3)); err != nil {
+		t.Fatalf("SaveLocations: %v", err)
+	}
+
+	store, err := Open(KindBolt, jsonPath, boltPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	loaded, err := store.LoadLocations()
+	if err != nil || !reflect.DeepEqual(loaded, testLocations(3)) {
+		t.Fatalf("seeded %+v, %v; want the JSON locations", loaded, err)
+	}
+
+	// Edits in the database survive a restart and are not overwritten by the JSON file
+	if err := store.SaveLocations(testLocations(1)); err != nil {
+		t.Fatalf("SaveLocations: %v", err)
+	}
+	store.Close()
+
+	store, err = Open(KindBolt, jsonPath, boltPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+	loaded, err = store.LoadLocations()
+	if err != nil || !reflect.DeepEqual(loaded, testLocations(1)) {
+		t.Errorf("reopened %+v, %v; want the edited location", loaded, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	// Without a JSON file a new bolt store starts empty
+	store, err := Open(KindBolt, filepath.Join(dir, "missing.json"), filepath.Join(dir, "locations.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	loaded, err := store.LoadLocations()
+	store.Close()
+	if err != nil || len(loaded) != 0 {
+		t.Errorf("new store has %+v, %v; want no locations", loaded, err)
+	}
+
+	if _, err := Open("sqlite", "", ""); err == nil {
+		t.Error("Open accepted an unknown store kind")
+	}
+
+	// Only the JSON store can change outside the server
+	if _, ok := LocationStore(NewJSONStore(filepath.Join(dir, "locations.json"))).(Watchable); !ok {
+		t.Error("JSON store is not watchable")
+	}
+}
+
+// testHolidays returns a calendar of n numbered hartal days
+func testHolidays(n int) *models.HolidayCalendar {
+	calendar := &models.HolidayCalendar{Holidays: make([]models.Holiday, n)}
+	for i := range calendar.Holidays {
+		calendar.Holidays[i] = models.Holiday{Date: fmt.Sprintf("2026-06-%02d", i+1), Name: fmt.Sprintf("Hartal %d", i+1), Type: "hartal"}
+	}
+	return calendar
+}
+
+func TestHolidayStoresRoundTrip(t *testing.T) {
+	stores := map[Kind]func(t *testing.T) HolidayStore{
+		KindJSON: func(t *testing.T) HolidayStore {
+			return NewJSONHolidayStore(filepath.Join(t.TempDir(), "holidays.json"))
+		},
+		KindBolt: func(t *testing.T) HolidayStore {
+			store, err := OpenBoltStore(filepath.Join(t.TempDir(), "locations.db"))
+			if err != nil {
+				t.Fatalf("OpenBoltStore: %v", err)
+			}
+			t.Cleanup(func() { store.Close() })
+			return store
+		},
+	}
+
+	for kind, open := range stores {
+		t.Run(string(kind), func(t *testing.T) {
+			store := open(t)
+			for _, calendar := range []*models.HolidayCalendar{testHolidays(3), testHolidays(1)} {
+				if err := store.SaveHolidays(calendar); err != nil {
+					t.Fatalf("SaveHolidays: %v", err)
+				}
+				loaded, err := store.LoadHolidays()
+				if err != nil {
+					t.Fatalf("LoadHolidays: %v", err)
+				}
+				if !reflect.DeepEqual(loaded, calendar) {
+					t.Errorf("loaded %+v, want %+v", loaded.Holidays, calendar.Holidays)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenHolidays(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath, boltPath := filepath.Join(dir, "holidays.json"), filepath.Join(dir, "locations.db")
+	if err := NewJSONHolidayStore(jsonPath).SaveHolidays(testHolidays(2)); err != nil {
+		t.Fatalf("SaveHolidays: %v", err)
+	}
+
+	// Alongside a JSON location store the holidays stay in their JSON file
+	holidays, err := OpenHolidays(NewJSONStore(filepath.Join(dir, "locations.json")), jsonPath)
+	if err != nil {
+		t.Fatalf("OpenHolidays: %v", err)
+	}
+	if _, ok := holidays.(*JSONHolidayStore); !ok {
+		t.Errorf("opened a %T next to a JSON location store, want the JSON file", holidays)
+	}
+
+	// A bolt database is seeded from the JSON file and then keeps its own edits
+	for _, want := range []*models.HolidayCalendar{testHolidays(2), testHolidays(3)} {
+		locations, err := Open(KindBolt, filepath.Join(dir, "missing.json"), boltPath)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		holidays, err := OpenHolidays(locations, jsonPath)
+		if err != nil {
+			t.Fatalf("OpenHolidays: %v", err)
+		}
+		loaded, err := holidays.LoadHolidays()
+		if err != nil || !reflect.DeepEqual(loaded, want) {
+			t.Errorf("loaded %+v, %v; want %+v", loaded, err, want.Holidays)
+		}
+		if err := holidays.SaveHolidays(testHolidays(3)); err != nil {
+			t.Fatalf("SaveHolidays: %v", err)
+		}
+		locations.Close()
+	}
+}
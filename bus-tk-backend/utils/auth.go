@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// RequireAdmin wraps a handler so it only serves requests carrying the admin
+// token as "Authorization: Bearer <token>". With an empty token the admin API is
+// disabled. CORS preflight requests are passed through unchecked.
+func RequireAdmin(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			next(w, r)
+			return
+		}
+		if token == "" {
+			http.Error(w, "Admin API is disabled", http.StatusForbidden)
+			return
+		}
+
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
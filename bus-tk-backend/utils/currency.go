@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Poisha is an amount of money in poisha; 100 poisha make one Taka.
+// Fares are calculated in whole poisha so repeated steps do not drift.
+type Poisha int64
+
+// TakaToPoisha converts Taka to poisha, rounding to the nearest poisha
+func TakaToPoisha(taka float64) Poisha {
+	return Poisha(math.Round(taka * 100))
+}
+
+// Taka converts the amount to Taka
+func (p Poisha) Taka() float64 {
+	return float64(p) / 100
+}
+
+// Scale multiplies the amount by a factor, rounding to the nearest poisha
+func (p Poisha) Scale(factor float64) Poisha {
+	return Poisha(math.Round(float64(p) * factor))
+}
+
+// Percent returns the given percentage of the amount, rounded to the nearest poisha
+func (p Poisha) Percent(percentage float64) Poisha {
+	return p.Scale(percentage / 100)
+}
+
+// RoundNearest rounds to the nearest multiple of step, halves rounding up
+func (p Poisha) RoundNearest(step Poisha) Poisha {
+	return (p + step/2) / step * step
+}
+
+// RoundUp rounds up to a multiple of step
+func (p Poisha) RoundUp(step Poisha) Poisha {
+	return (p + step - 1) / step * step
+}
+
+// RoundDown rounds down to a multiple of step
+func (p Poisha) RoundDown(step Poisha) Poisha {
+	return p / step * step
+}
+
+// FormatTaka formats an amount in English, e.g. "Tk 65" or "Tk 67.20"
+func FormatTaka(p Poisha) string {
+	return "Tk " + formatAmount(p)
+}
+
+// FormatTakaBn formats an amount in Bengali numerals, e.g. "৬৫ টাকা"
+func FormatTakaBn(p Poisha) string {
+	return BengaliDigits(formatAmount(p)) + " টাকা"
+}
+
+// formatAmount formats whole Taka without decimals and anything else with two
+func formatAmount(p Poisha) string {
+	sign := ""
+	if p < 0 {
+		sign, p = "-", -p
+	}
+	if p%100 == 0 {
+		return fmt.Sprintf("%s%d", sign, p/100)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, p/100, p%100)
+}
+
+// bengaliDigits maps ASCII digits to Bengali numerals
+var bengaliDigits = strings.NewReplacer(
+	"0", "০", "1", "১", "2", "২", "3", "৩", "4", "৪",
+	"5", "৫", "6", "৬", "7", "৭", "8", "৮", "9", "৯",
+)
+
+// BengaliDigits replaces the ASCII digits in a string with Bengali numerals
+func BengaliDigits(value string) string {
+	return bengaliDigits.Replace(value)
+}
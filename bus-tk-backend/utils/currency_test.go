@@ -0,0 +1,71 @@
+package utils
+
+import "testing"
+
+func TestPoishaRounding(t *testing.T) {
+	tests := []struct {
+		amount      Poisha
+		step        Poisha
+		wantNearest Poisha
+		wantUp      Poisha
+		wantDown    Poisha
+	}{
+		{amount: 6720, step: 100, wantNearest: 6700, wantUp: 6800, wantDown: 6700},
+		{amount: 6750, step: 100, wantNearest: 6800, wantUp: 6800, wantDown: 6700},
+		{amount: 6700, step: 100, wantNearest: 6700, wantUp: 6700, wantDown: 6700},
+		{amount: 6249, step: 500, wantNearest: 6000, wantUp: 6500, wantDown: 6000},
+		{amount: 6250, step: 500, wantNearest: 6500, wantUp: 6500, wantDown: 6000},
+		{amount: 1, step: 50, wantNearest: 0, wantUp: 50, wantDown: 0},
+		{amount: 0, step: 500, wantNearest: 0, wantUp: 0, wantDown: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.RoundNearest(tt.step); got != tt.wantNearest {
+			t.Errorf("%d.RoundNearest(%d) = %d, want %d", tt.amount, tt.step, got, tt.wantNearest)
+		}
+		if got := tt.amount.RoundUp(tt.step); got != tt.wantUp {
+			t.Errorf("%d.RoundUp(%d) = %d, want %d", tt.amount, tt.step, got, tt.wantUp)
+		}
+		if got := tt.amount.RoundDown(tt.step); got != tt.wantDown {
+			t.Errorf("%d.RoundDown(%d) = %d, want %d", tt.amount, tt.step, got, tt.wantDown)
+		}
+	}
+}
+
+func TestPoishaArithmetic(t *testing.T) {
+	if got := TakaToPoisha(2.345); got != 235 {
+		t.Errorf("TakaToPoisha(2.345) = %d, want 235", got)
+	}
+	if got := TakaToPoisha(0.1 + 0.2); got != 30 {
+		t.Errorf("TakaToPoisha(0.1 + 0.2) = %d, want 30", got)
+	}
+	if got := Poisha(2450).Scale(1.25); got != 3063 {
+		t.Errorf("Scale(1.25) = %d, want 3063", got)
+	}
+	if got := Poisha(4999).Percent(50); got != 2500 {
+		t.Errorf("Percent(50) = %d, want 2500", got)
+	}
+}
+
+func TestFormatTaka(t *testing.T) {
+	tests := []struct {
+		amount Poisha
+		want   string
+		wantBn string
+	}{
+		{6500, "Tk 65", "৬৫ টাকা"},
+		{6720, "Tk 67.20", "৬৭.২০ টাকা"},
+		{5, "Tk 0.05", "০.০৫ টাকা"},
+		{0, "Tk 0", "০ টাকা"},
+		{-250, "Tk -2.50", "-২.৫০ টাকা"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTaka(tt.amount); got != tt.want {
+			t.Errorf("FormatTaka(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+		if got := FormatTakaBn(tt.amount); got != tt.wantBn {
+			t.Errorf("FormatTakaBn(%d) = %q, want %q", tt.amount, got, tt.wantBn)
+		}
+	}
+}
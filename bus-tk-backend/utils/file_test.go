@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	// A new file is readable by everyone
+	if err := WriteJSONFile(path, map[string]int{"version": 1}); err != nil {
+		t.Fatalf("WriteJSONFile: %v", err)
+	}
+	checkFile(t, path, 0644, "{\n  \"version\": 1\n}")
+
+	// Replacing a file keeps its permissions
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	if err := WriteJSONFile(path, map[string]int{"version": 2}); err != nil {
+		t.Fatalf("WriteJSONFile: %v", err)
+	}
+	checkFile(t, path, 0640, "{\n  \"version\": 2\n}")
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only data.json", len(entries))
+	}
+}
+
+func TestWriteJSONFileKeepsFileOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := WriteJSONFile(path, []int{1}); err != nil {
+		t.Fatalf("WriteJSONFile: %v", err)
+	}
+
+	// A value that cannot be encoded leaves the previous content in place
+	if err := WriteJSONFile(path, func() {}); err == nil {
+		t.Fatal("WriteJSONFile encoded a function")
+	}
+	checkFile(t, path, 0644, "[\n  1\n]")
+}
+
+// checkFile checks a file's permissions and content
+func checkFile(t *testing.T, path string, mode os.FileMode, content string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), mode)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("content = %q, want %q", data, content)
+	}
+}
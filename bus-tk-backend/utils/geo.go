@@ -0,0 +1,18 @@
+package utils
+
+import "math"
+
+// earthRadiusMeters is the mean radius of the Earth
+const earthRadiusMeters = 6371000.0
+
+// HaversineMeters returns the great-circle distance in meters between two coordinates
+func HaversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaPhi := (lat2 - lat1) * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
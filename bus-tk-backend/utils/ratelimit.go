@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter allows each client a fixed number of requests per time window
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time // Clock, replaced in tests
+
+	mu      sync.Mutex
+	clients map[string]*rateWindow
+}
+
+// rateWindow counts a client's requests in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter creates a limiter allowing limit requests per window for each client
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+// Allow records a request from a client and reports whether it is within the limit.
+// When it is not, it also returns how long until the client's window resets.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget expired windows once the map grows, so idle clients do not pile up
+	if len(l.clients) >= 10000 {
+		for key, window := range l.clients {
+			if now.Sub(window.start) >= l.window {
+				delete(l.clients, key)
+			}
+		}
+	}
+
+	window := l.clients[client]
+	if window == nil || now.Sub(window.start) >= l.window {
+		window = &rateWindow{start: now}
+		l.clients[client] = window
+	}
+	if window.count >= l.limit {
+		return false, window.start.Add(l.window).Sub(now)
+	}
+	window.count++
+	return true, 0
+}
+
+// RateLimit wraps a handler so each client IP may call it at most as often as the
+// limiter allows. A nil limiter disables the limit. CORS preflight requests are not counted.
+func RateLimit(limiter *RateLimiter, next http.HandlerFunc) http.HandlerFunc {
+	if limiter == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			next(w, r)
+			return
+		}
+
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if ok, retryAfter := limiter.Allow(client); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestRateLimiter returns a limiter whose clock only moves when advance is called
+func newTestRateLimiter(limit int, window time.Duration) (*RateLimiter, func(time.Duration)) {
+	limiter := NewRateLimiter(limit, window)
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter, advance := newTestRateLimiter(3, time.Hour)
+
+	steps := []struct {
+		name          string
+		advance       time.Duration
+		client        string
+		wantAllowed   bool
+		wantRetryLeft time.Duration
+	}{
+		{name: "burst 1", client: "a", wantAllowed: true},
+		{name: "burst 2", client: "a", wantAllowed: true},
+		{name: "burst 3", advance: 10 * time.Minute, client: "a", wantAllowed: true},
+		{name: "over the limit", advance: 20 * time.Minute, client: "a", wantRetryLeft: 30 * time.Minute},
+		{name: "other client keeps its own limit", client: "b", wantAllowed: true},
+		{name: "still limited before the window ends", advance: 30*time.Minute - time.Second, client: "a", wantRetryLeft: time.Second},
+		{name: "refilled when the window ends", advance: time.Second, client: "a", wantAllowed: true},
+		{name: "refilled window starts a new burst", client: "a", wantAllowed: true},
+		{name: "other client's window started later", advance: 30 * time.Minute, client: "b", wantAllowed: true},
+	}
+
+	for _, step := range steps {
+		advance(step.advance)
+		allowed, retryAfter := limiter.Allow(step.client)
+		if allowed != step.wantAllowed || retryAfter != step.wantRetryLeft {
+			t.Errorf("%s: got %v (retry after %v), want %v (retry after %v)", step.name, allowed, retryAfter, step.wantAllowed, step.wantRetryLeft)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter, _ := newTestRateLimiter(1, time.Hour)
+	handler := RateLimit(limiter, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		remoteAddr     string
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "first request", method: "POST", remoteAddr: "10.0.0.1:5000", wantStatus: http.StatusCreated},
+		{name: "preflight is not counted", method: "OPTIONS", remoteAddr: "10.0.0.1:5001", wantStatus: http.StatusCreated},
+		{name: "same IP from another port", method: "POST", remoteAddr: "10.0.0.1:5002", wantStatus: http.StatusTooManyRequests, wantRetryAfter: "3601"},
+		{name: "another IP", method: "POST", remoteAddr: "10.0.0.2:5000", wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/api/reports", nil)
+			request.RemoteAddr = tt.remoteAddr
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+
+			if recorder.Code != tt.wantStatus || recorder.Header().Get("Retry-After") != tt.wantRetryAfter {
+				t.Errorf("got %d (Retry-After %q), want %d (%q)", recorder.Code, recorder.Header().Get("Retry-After"), tt.wantStatus, tt.wantRetryAfter)
+			}
+		})
+	}
+
+	if RateLimit(nil, nil) != nil {
+		t.Error("a nil limiter should leave the handler unwrapped")
+	}
+}